@@ -0,0 +1,170 @@
+package toast
+
+// DefaultAction is the id of the action invoked when the notification itself is clicked,
+// it isn't displayed as a button.
+const DefaultAction = "default"
+
+// maxActions is the most buttons a notification displays, and the most inputs.
+const maxActions = 5
+
+// WithAction
+//
+// Adds a button to the notification, fn is called with the id when the user clicks it.
+// Use DefaultAction as the id to be called back when the notification itself is clicked.
+// The callback keeps the backend listening until the notification is closed.
+func WithAction(id, label string, fn func(ActionEvent)) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithAction")
+		if len(n.Actions) == maxActions {
+			return
+		}
+		n.Actions = append(n.Actions, Action{
+			Type:      "background",
+			Label:     label,
+			Arguments: id,
+			ID:        id,
+			fn:        fn,
+		})
+	}
+}
+
+// WithTextInput
+//
+// Adds a text box to the notification, its value is passed to the callbacks of the actions in ActionEvent.Inputs.
+// The action with the same id is its send button: displayed next to the box on Windows,
+// and the inline reply of the freedesktop servers supporting it (like KDE Plasma).
+func WithTextInput(id, placeholder string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithTextInput")
+		if len(n.Inputs) == maxActions {
+			return
+		}
+		n.Inputs = append(n.Inputs, Input{ID: id, Type: "text", Placeholder: placeholder})
+	}
+}
+
+// WithSelection
+//
+// Adds a selection box to the notification (Windows only), the id of the selected choice
+// is passed to the callbacks of the actions in ActionEvent.Inputs.
+func WithSelection(id string, choices []Choice) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithSelection")
+		if len(n.Inputs) == maxActions {
+			return
+		}
+		n.Inputs = append(n.Inputs, Input{ID: id, Type: "selection", Choices: append([]Choice(nil), choices...)})
+	}
+}
+
+// Input is a text box or a selection box, see WithTextInput and WithSelection.
+type Input struct {
+	ID string `json:"id" yaml:"id"`
+
+	// text or selection
+	Type string `json:"type" yaml:"type"`
+
+	// The text displayed in an empty text box
+	Placeholder string `json:"placeholder,omitempty" yaml:"placeholder,omitempty"`
+
+	// The choices of a selection box
+	Choices []Choice `json:"choices,omitempty" yaml:"choices,omitempty"`
+}
+
+// Choice is an item of a selection box.
+type Choice struct {
+	// The id passed to the callback
+	ID string `json:"id" yaml:"id"`
+
+	Label string `json:"label" yaml:"label"`
+}
+
+// Action
+//
+// Defines an actionable button.
+// See https://msdn.microsoft.com/en-us/windows/uwp/controls-and-patterns/tiles-and-notifications-adaptive-interactive-toasts for more info.
+//
+// Buttons added with WithAction call back into the application, protocol type buttons launch an URI instead.
+// Examples of protocol type action buttons include: "bingmaps:?q=sushi" to open up Windows 10's
+// maps app with a pre-populated search field set to "sushi".
+//
+//	Action{Type: "protocol", Label: "Open Maps", Arguments: "bingmaps:?q=sushi"}
+type Action struct {
+	// The Windows activation type of the button
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// The text of the button
+	Label string `json:"label" yaml:"label"`
+
+	// The Windows activation arguments of the button
+	Arguments string `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+
+	// The id passed to the callback
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	fn func(ActionEvent)
+}
+
+// ActionEvent is passed to the callback of an action.
+type ActionEvent struct {
+	// The id of the notification, see Handle.ID
+	NotificationID string
+
+	// The id of the clicked action, DefaultAction if the notification itself was clicked
+	ActionID string
+
+	// The values of the text boxes and the ids of the selected choices, by input id
+	Inputs map[string]string
+}
+
+// DispatchAction calls the callback of the action with the id ev.ActionID,
+// backends use it to deliver the user's clicks.
+// It reports whether there was such a callback.
+func (n *Notification) DispatchAction(ev ActionEvent) bool {
+	if len(ev.NotificationID) == 0 {
+		ev.NotificationID = n.ID
+	}
+	for _, a := range n.Actions {
+		if a.ID == ev.ActionID && a.fn != nil {
+			a.fn(ev)
+			return true
+		}
+	}
+	return false
+}
+
+// hasCallbacks reports whether the backend needs to listen for the user's clicks.
+func (n *Notification) hasCallbacks() bool {
+	for _, a := range n.Actions {
+		if a.fn != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// replyInput returns the text input with a send button, nil if there's none.
+func (n *Notification) replyInput() *Input {
+	for i, in := range n.Inputs {
+		if in.Type != "text" {
+			continue
+		}
+		for _, a := range n.Actions {
+			if a.ID == in.ID && a.fn != nil {
+				return &n.Inputs[i]
+			}
+		}
+	}
+	return nil
+}
+
+// buttons returns the actions displayed as buttons, that is all but DefaultAction.
+func (n *Notification) buttons() []Action {
+	buttons := make([]Action, 0, len(n.Actions))
+	for _, a := range n.Actions {
+		if a.ID != DefaultAction || a.fn == nil {
+			buttons = append(buttons, a)
+		}
+	}
+	return buttons
+}
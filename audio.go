@@ -0,0 +1,63 @@
+package toast
+
+// Any Audio may be given on any platform, these are the ones each platform knows.
+
+// Windows
+const (
+	Silent         Audio = "silent"
+	Default        Audio = "ms-winsoundevent:Notification.Default"
+	IM             Audio = "ms-winsoundevent:Notification.IM"
+	Mail           Audio = "ms-winsoundevent:Notification.Mail"
+	Reminder       Audio = "ms-winsoundevent:Notification.Reminder"
+	SMS            Audio = "ms-winsoundevent:Notification.SMS"
+	LoopingAlarm   Audio = "ms-winsoundevent:Notification.Looping.Alarm"
+	LoopingAlarm2  Audio = "ms-winsoundevent:Notification.Looping.Alarm2"
+	LoopingAlarm3  Audio = "ms-winsoundevent:Notification.Looping.Alarm3"
+	LoopingAlarm4  Audio = "ms-winsoundevent:Notification.Looping.Alarm4"
+	LoopingAlarm5  Audio = "ms-winsoundevent:Notification.Looping.Alarm5"
+	LoopingAlarm6  Audio = "ms-winsoundevent:Notification.Looping.Alarm6"
+	LoopingAlarm7  Audio = "ms-winsoundevent:Notification.Looping.Alarm7"
+	LoopingAlarm8  Audio = "ms-winsoundevent:Notification.Looping.Alarm8"
+	LoopingAlarm9  Audio = "ms-winsoundevent:Notification.Looping.Alarm9"
+	LoopingAlarm10 Audio = "ms-winsoundevent:Notification.Looping.Alarm10"
+	LoopingCall    Audio = "ms-winsoundevent:Notification.Looping.Call"
+	LoopingCall2   Audio = "ms-winsoundevent:Notification.Looping.Call2"
+	LoopingCall3   Audio = "ms-winsoundevent:Notification.Looping.Call3"
+	LoopingCall4   Audio = "ms-winsoundevent:Notification.Looping.Call4"
+	LoopingCall5   Audio = "ms-winsoundevent:Notification.Looping.Call5"
+	LoopingCall6   Audio = "ms-winsoundevent:Notification.Looping.Call6"
+	LoopingCall7   Audio = "ms-winsoundevent:Notification.Looping.Call7"
+	LoopingCall8   Audio = "ms-winsoundevent:Notification.Looping.Call8"
+	LoopingCall9   Audio = "ms-winsoundevent:Notification.Looping.Call9"
+	LoopingCall10  Audio = "ms-winsoundevent:Notification.Looping.Call10"
+)
+
+// macOS
+const (
+	Basso     Audio = "Basso"
+	Blow      Audio = "Blow"
+	Bottle    Audio = "Bottle"
+	Frog      Audio = "Frog"
+	Funk      Audio = "Funk"
+	Glass     Audio = "Glass"
+	Hero      Audio = "Hero"
+	Morse     Audio = "Morse"
+	Ping      Audio = "Ping"
+	Pop       Audio = "Pop"
+	Purr      Audio = "Purr"
+	Sosumi    Audio = "Sosumi"
+	Submarine Audio = "Submarine"
+	Tink      Audio = "Tink"
+)
+
+// freedesktop
+// https://specifications.freedesktop.org/sound-naming-spec/latest/
+const (
+	Bell              Audio = "bell"
+	Complete          Audio = "complete"
+	DialogError       Audio = "dialog-error"
+	DialogInformation Audio = "dialog-information"
+	DialogWarning     Audio = "dialog-warning"
+	MessageNewEmail   Audio = "message-new-email"
+	MessageNewInstant Audio = "message-new-instant"
+)
@@ -0,0 +1,83 @@
+package toast
+
+import (
+	"context"
+	"errors"
+)
+
+// Caps describes what a backend is able to display.
+type Caps struct {
+	// The callbacks of WithAction are called
+	Actions bool
+
+	// The message may contain markup (a subset of HTML on freedesktop servers)
+	BodyMarkup bool
+
+	// Icons and images are displayed
+	Images bool
+
+	// Audio is played
+	Sounds bool
+
+	// Notifications are kept (in the Action Center, the notification center, ...) after they expired
+	Persistence bool
+
+	// Text boxes are displayed, selection boxes too on Windows
+	Inputs bool
+
+	// Progress bars are displayed
+	Progress bool
+
+	// The most buttons displayed with a notification
+	MaxActions int
+}
+
+// CapsReporter is implemented by the backends which know what they are able to display.
+type CapsReporter interface {
+	Capabilities(ctx context.Context) (Caps, error)
+}
+
+// Capabilities returns what the backend selected by Use is able to display,
+// the zero Caps if it can't tell.
+func Capabilities() Caps {
+	return CapabilitiesContext(context.Background())
+}
+
+// CapabilitiesContext is like Capabilities but gives up once ctx is done.
+func CapabilitiesContext(ctx context.Context) Caps {
+	nf, err := current()
+	if err != nil {
+		return Caps{}
+	}
+	caps, _ := capabilities(ctx, nf)
+	return caps
+}
+
+var errNoCaps = errors.New("toast: backend doesn't report its capabilities")
+
+func capabilities(ctx context.Context, nf Notifier) (Caps, error) {
+	reporter, ok := nf.(CapsReporter)
+	if !ok {
+		return Caps{}, errNoCaps
+	}
+	return reporter.Capabilities(ctx)
+}
+
+// Capabilities are the ones of the first notifier able to report them,
+// which is the one expected to display the notifications.
+func (c chain) Capabilities(ctx context.Context) (Caps, error) {
+	for _, nf := range c {
+		if caps, err := capabilities(ctx, nf); err == nil {
+			return caps, nil
+		}
+	}
+	return Caps{}, errNoCaps
+}
+
+func (name registered) Capabilities(ctx context.Context) (Caps, error) {
+	nf, err := newNotifier(string(name))
+	if err != nil {
+		return Caps{}, err
+	}
+	return capabilities(ctx, nf)
+}
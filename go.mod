@@ -1,3 +1,8 @@
 module github.com/electricbubble/go-toast
 
 go 1.17
+
+require (
+	github.com/godbus/dbus/v5 v5.1.0
+	gopkg.in/yaml.v3 v3.0.1
+)
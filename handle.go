@@ -0,0 +1,97 @@
+package toast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Closer is implemented by the backends able to withdraw a notification they displayed.
+type Closer interface {
+	Close(ctx context.Context, n *Notification) error
+}
+
+// Handle refers to a notification displayed by Send.
+type Handle struct {
+	mu       sync.Mutex
+	notifier Notifier
+	n        *Notification
+}
+
+// ID returns the id the backend assigned to the notification:
+// the freedesktop notification id, the Windows toast Tag or the web notification tag.
+// It is empty if the backend has no way of referring to a notification.
+func (h *Handle) ID() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.n.ID
+}
+
+// Update applies opts to the notification and displays it again,
+// replacing the previous one if the backend supports it.
+func (h *Handle) Update(opts ...NotificationOption) error {
+	return h.UpdateContext(context.Background(), opts...)
+}
+
+// UpdateContext is like Update, giving up once ctx is done.
+func (h *Handle) UpdateContext(ctx context.Context, opts ...NotificationOption) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, fn := range opts {
+		fn(h.n)
+	}
+	return notify(ctx, h.notifier, h.n)
+}
+
+// progressUpdater is implemented by the backends able to update the progress bar without displaying the notification again.
+type progressUpdater interface {
+	updateProgress(ctx context.Context, n *Notification) error
+}
+
+// SetProgress updates the value of the progress bar of a notification displayed WithProgress,
+// in place rather than as a new notification. Update with WithProgress changes the texts too.
+func (h *Handle) SetProgress(value float64) error {
+	return h.SetProgressContext(context.Background(), value)
+}
+
+// SetProgressContext is like SetProgress, giving up once ctx is done.
+func (h *Handle) SetProgressContext(ctx context.Context, value float64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.n.Progress == nil {
+		return errors.New("toast: SetProgress on a notification without WithProgress")
+	}
+	// the copies of the notification the backend kept are left untouched
+	progress := *h.n.Progress
+	progress.Value = value
+	h.n.Progress = &progress
+	if u, ok := h.notifier.(progressUpdater); ok {
+		return u.updateProgress(ctx, h.n)
+	}
+	return notify(ctx, h.notifier, h.n)
+}
+
+// Close withdraws the notification.
+// ErrNotSupported is returned if the backend can't do that.
+func (h *Handle) Close() error {
+	return h.CloseContext(context.Background())
+}
+
+// CloseContext is like Close, giving up once ctx is done.
+func (h *Handle) CloseContext(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	closer, ok := h.notifier.(Closer)
+	if !ok {
+		return fmt.Errorf("%w: closing a notification", ErrNotSupported)
+	}
+	return closer.Close(ctx, h.n)
+}
+
+// delivered records which Notifier displayed n, so that its Handle talks to the same backend.
+func delivered(n *Notification, nf Notifier) {
+	if n.sender == nil {
+		n.sender = nf
+	}
+}
@@ -0,0 +1,195 @@
+package toast
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// the options giving the content of the icon rather than a path
+var iconOptions = []string{"WithIconRaw", "WithIconImage", "WithIconFS", "WithIconReader"}
+
+// IconWriter is implemented by the backends handling the icon given WithIconRaw and the like themselves,
+// if WritesIcon is true. It's written to a file for the others before Notify, n.Icon is its path.
+type IconWriter interface {
+	WritesIcon() bool
+}
+
+// needsIconFile reports whether notify writes the icon of n to a file for nf.
+func needsIconFile(ctx context.Context, nf Notifier, n *Notification) bool {
+	if n._iconRaw == nil && n._iconLoad == nil {
+		return false
+	}
+	if w, ok := nf.(IconWriter); ok && w.WritesIcon() {
+		return false
+	}
+	d, ok := nf.(optionDropper)
+	if !ok {
+		return true
+	}
+	for _, name := range d.droppedOptions(ctx, n) {
+		for _, option := range iconOptions {
+			if name == option {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// loadIcon returns the content of the icon given WithIconRaw and the like, nil if there's none.
+// It's loaded once, when the notification is first pushed.
+func loadIcon(n *Notification) ([]byte, error) {
+	if n._iconLoad != nil {
+		raw, err := n._iconLoad()
+		if err != nil {
+			return nil, fmt.Errorf("toast: icon: %w", err)
+		}
+		n._iconRaw, n._iconLoad = raw, nil
+	}
+	return n._iconRaw, nil
+}
+
+// iconDataURL returns the icon of n as a data: URL, scaled down to fit size x size pixels,
+// n.Icon if it wasn't given WithIconRaw and the like.
+func iconDataURL(n *Notification, size int) (string, error) {
+	raw, err := loadIcon(n)
+	if err != nil || raw == nil {
+		return n.Icon, err
+	}
+	if raw, err = fitIcon(raw, size); err != nil {
+		return "", err
+	}
+	_, mimeType, err := imageFormat(raw)
+	if err != nil {
+		return "", err
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// iconCacheTTL is how long an icon written for WithIconRaw stays in the cache once it's no longer used.
+const iconCacheTTL = 24 * time.Hour
+
+var _iconGC sync.Once
+
+// iconCacheDir returns the directory of the icons written for WithIconRaw.
+func iconCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "go-toast", "icons")
+}
+
+// materializeIcon writes the image given WithIconRaw and the like to the cache, named after its content so that
+// the notifications with the same icon share the file, and sets n.Icon to its path.
+// It's scaled down to fit size x size pixels first.
+func materializeIcon(n *Notification, size int) error {
+	raw, err := loadIcon(n)
+	if err != nil || raw == nil {
+		return err
+	}
+	if raw, err = fitIcon(raw, size); err != nil {
+		return err
+	}
+	ext, _, err := imageFormat(raw)
+	if err != nil {
+		return err
+	}
+	dir := iconCacheDir()
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("toast: icon: %w", err)
+	}
+	// the files aren't removed once displayed: the notification center loads them later on
+	_iconGC.Do(func() {
+		removeStaleIcons(dir, time.Now().Add(-iconCacheTTL))
+	})
+
+	sum := sha256.Sum256(raw)
+	path := filepath.Join(dir, hex.EncodeToString(sum[:16])+ext)
+	if info, err := os.Stat(path); err == nil && info.Size() == int64(len(raw)) {
+		// used again, not stale
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+	} else if err = writeIcon(path, raw); err != nil {
+		return fmt.Errorf("toast: icon: %w", err)
+	}
+	n.Icon = path
+	return nil
+}
+
+// writeIcon writes the file at once, so that no notification displays it half written.
+func writeIcon(path string, raw []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(raw)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
+
+// removeStaleIcons removes the files of the cache not used since before, including those left half written.
+func removeStaleIcons(dir string, before time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.ModTime().After(before) {
+			continue
+		}
+		_ = os.Remove(filepath.Join(dir, entry.Name()))
+	}
+}
+
+// the signatures of the image formats the notification centers display
+var imageSignatures = []struct {
+	ext, mimeType string
+	prefix        string
+}{
+	{".png", "image/png", "\x89PNG\r\n\x1a\n"},
+	{".jpg", "image/jpeg", "\xff\xd8\xff"},
+	{".gif", "image/gif", "GIF87a"},
+	{".gif", "image/gif", "GIF89a"},
+	{".bmp", "image/bmp", "BM"},
+	{".ico", "image/x-icon", "\x00\x00\x01\x00"},
+}
+
+// imageFormat returns the file extension and the MIME type of the image, sniffed from its content.
+func imageFormat(raw []byte) (ext, mimeType string, err error) {
+	for _, sig := range imageSignatures {
+		if bytes.HasPrefix(raw, []byte(sig.prefix)) {
+			return sig.ext, sig.mimeType, nil
+		}
+	}
+	if len(raw) >= 12 && string(raw[:4]) == "RIFF" && string(raw[8:12]) == "WEBP" {
+		return ".webp", "image/webp", nil
+	}
+	head := raw
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	if head = bytes.TrimSpace(head); bytes.HasPrefix(head, []byte("<svg")) ||
+		bytes.HasPrefix(head, []byte("<?xml")) && bytes.Contains(head, []byte("<svg")) {
+		return ".svg", "image/svg+xml", nil
+	}
+	return "", "", errors.New("toast: icon: unknown image format")
+}
@@ -0,0 +1,150 @@
+package toast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestImageFormat(t *testing.T) {
+	for _, tt := range []struct {
+		raw      string
+		expected string
+	}{
+		{"\x89PNG\r\n\x1a\n\x00\x00", ".png"},
+		{"\xff\xd8\xff\xe0", ".jpg"},
+		{"GIF89a", ".gif"},
+		{"BM\x00\x00", ".bmp"},
+		{"\x00\x00\x01\x00\x01\x00", ".ico"},
+		{"RIFF\x00\x00\x00\x00WEBPVP8 ", ".webp"},
+		{"\n <svg xmlns=\"http://www.w3.org/2000/svg\"/>", ".svg"},
+		{"<?xml version=\"1.0\"?>\n<svg/>", ".svg"},
+		{"<?xml version=\"1.0\"?>\n<html/>", ""},
+		{"not an image", ""},
+		{"", ""},
+	} {
+		ext, _, err := imageFormat([]byte(tt.raw))
+		if ext != tt.expected || (err != nil) != (tt.expected == "") {
+			t.Errorf("%q: expected %q, got %q (%v)", tt.raw, tt.expected, ext, err)
+		}
+	}
+}
+
+func TestWithIconRaw(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	nf := NotifierFunc(func(context.Context, *Notification) error { return nil })
+	png := []byte("\x89PNG\r\n\x1a\ntest")
+
+	n := &Notification{}
+	WithIconRaw(png)(n)
+	if len(n.Icon) != 0 {
+		t.Errorf("expected the icon to be written when pushed, got %q", n.Icon)
+	}
+	checkErr(t, notify(context.Background(), nf, n))
+	if filepath.Ext(n.Icon) != ".png" || filepath.Dir(n.Icon) != iconCacheDir() {
+		t.Errorf("expected a png in %s, got %q", iconCacheDir(), n.Icon)
+	}
+	bs, err := os.ReadFile(n.Icon)
+	checkErr(t, err)
+	if string(bs) != string(png) {
+		t.Errorf("unexpected content %q", bs)
+	}
+
+	// the same content is the same file
+	other := &Notification{}
+	WithIconRaw(png)(other)
+	checkErr(t, notify(context.Background(), nf, other))
+	if other.Icon != n.Icon {
+		t.Errorf("expected %q, got %q", n.Icon, other.Icon)
+	}
+
+	WithIcon("/path/icon.png")(other)
+	checkErr(t, notify(context.Background(), nf, other))
+	if other.Icon != "/path/icon.png" {
+		t.Errorf("expected the last icon given, got %q", other.Icon)
+	}
+
+	// the error is the one of the push
+	WithIconRaw([]byte("not an image"))(other)
+	if err = notify(context.Background(), nf, other); err == nil {
+		t.Error("expected an error for an unknown image format")
+	}
+	// unless the backend drops the option
+	checkErr(t, notify(context.Background(), honoring{}, other))
+}
+
+func TestWithIconImage(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	nf := NotifierFunc(func(context.Context, *Notification) error { return nil })
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(1, 0, color.NRGBA{R: 0xff, A: 0xff})
+	png := []byte("\x89PNG\r\n\x1a\ntest")
+
+	for name, opt := range map[string]NotificationOption{
+		"WithIconImage":  WithIconImage(img),
+		"WithIconFS":     WithIconFS(fstest.MapFS{"icons/app.png": {Data: png}}, "icons/app.png"),
+		"WithIconReader": WithIconReader(bytes.NewReader(png)),
+	} {
+		n := &Notification{}
+		opt(n)
+		checkErr(t, notify(context.Background(), nf, n))
+		bs, err := os.ReadFile(n.Icon)
+		checkErr(t, err)
+		if filepath.Ext(n.Icon) != ".png" || !bytes.HasPrefix(bs, png[:8]) {
+			t.Errorf("%s: expected a png, got %q", name, n.Icon)
+		}
+		// the reader is read once
+		checkErr(t, notify(context.Background(), nf, n))
+	}
+
+	// the errors are the ones of the push
+	n := &Notification{}
+	WithIconFS(fstest.MapFS{}, "app.png")(n)
+	if err := notify(context.Background(), nf, n); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %v, got %v", fs.ErrNotExist, err)
+	}
+	failing := errors.New("test_error")
+	WithIconReader(failingReader{failing})(n)
+	if err := notify(context.Background(), nf, n); !errors.Is(err, failing) {
+		t.Errorf("expected %v, got %v", failing, err)
+	}
+
+	WithIconReader(bytes.NewReader(png))(n)
+	url, err := iconDataURL(n, 0)
+	checkErr(t, err)
+	if url != "data:image/png;base64,iVBORw0KGgp0ZXN0" {
+		t.Errorf("unexpected data: URL %q", url)
+	}
+}
+
+type failingReader struct{ err error }
+
+func (r failingReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestRemoveStaleIcons(t *testing.T) {
+	dir := t.TempDir()
+	stale, fresh := filepath.Join(dir, "stale.png"), filepath.Join(dir, "fresh.png")
+	for _, name := range []string{stale, fresh, filepath.Join(dir, "half.tmp")} {
+		checkErr(t, os.WriteFile(name, nil, 0600))
+	}
+	old := time.Now().Add(-2 * iconCacheTTL)
+	checkErr(t, os.Chtimes(stale, old, old))
+	checkErr(t, os.Chtimes(filepath.Join(dir, "half.tmp"), old, old))
+
+	removeStaleIcons(dir, time.Now().Add(-iconCacheTTL))
+	entries, err := os.ReadDir(dir)
+	checkErr(t, err)
+	if len(entries) != 1 || entries[0].Name() != "fresh.png" {
+		t.Errorf("expected only the fresh icon to be kept, got %v", entries)
+	}
+}
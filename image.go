@@ -0,0 +1,147 @@
+package toast
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+)
+
+// the largest icons, in pixels, the backends display without scaling them down themselves
+const (
+	// a toast fails to show with a larger image
+	windowsIconSize = 1024
+	// image-data is sent along with the D-Bus message
+	freedesktopIconSize = 256
+	// the data: URL of a web notification
+	browserIconSize = 192
+)
+
+// maxImagePixels is the largest image decoded, 64 MB of pixels: the size of the others is set by whoever
+// gives the image, who may be anyone when it's read WithIconReader.
+const maxImagePixels = 4096 * 4096
+
+// iconSizer is implemented by the Notifiers limiting the size of the icons,
+// the icon given WithIconRaw and the like is scaled down to fit maxIconSize x maxIconSize pixels.
+type iconSizer interface {
+	maxIconSize() int
+}
+
+// maxIconSize returns the limit of nf, zero if there's none.
+func maxIconSize(nf Notifier) int {
+	if s, ok := nf.(iconSizer); ok {
+		return s.maxIconSize()
+	}
+	return 0
+}
+
+// fitIcon returns the PNG, JPEG or GIF image scaled down to fit size x size pixels and encoded to PNG,
+// raw as is if it already fits, size is zero or it's another format.
+func fitIcon(raw []byte, size int) ([]byte, error) {
+	if size <= 0 {
+		return raw, nil
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil || config.Width <= size && config.Height <= size {
+		return raw, nil
+	}
+	img, err := decodeLimited(raw, config)
+	if err != nil || img == nil {
+		return raw, err
+	}
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, scaleImage(img, size)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeImage returns the pixels of the PNG, JPEG or GIF image scaled down to fit size x size pixels
+// (unless size is zero), nil if it's another format.
+func decodeImage(raw []byte, size int) (*image.NRGBA, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil
+	}
+	img, err := decodeLimited(raw, config)
+	if err != nil || img == nil {
+		return nil, err
+	}
+	return scaleImage(img, size), nil
+}
+
+// decodeLimited decodes the image of the given config, which has to be at most maxImagePixels,
+// nil if it fails to.
+func decodeLimited(raw []byte, config image.Config) (image.Image, error) {
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, nil
+	}
+	if config.Width > maxImagePixels/config.Height {
+		return nil, fmt.Errorf("toast: icon: %dx%d image exceeds %d pixels", config.Width, config.Height, maxImagePixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil
+	}
+	return img, nil
+}
+
+// fitSize returns the size of a width x height image scaled down to fit size x size, keeping its aspect ratio.
+func fitSize(width, height, size int) (int, int) {
+	if size <= 0 || width <= size && height <= size {
+		return width, height
+	}
+	if width >= height {
+		return size, roundedDiv(height*size, width)
+	}
+	return roundedDiv(width*size, height), size
+}
+
+// roundedDiv returns a / b rounded to the nearest integer, at least 1.
+func roundedDiv(a, b int) int {
+	if q := (2*a + b) / (2 * b); q > 0 {
+		return q
+	}
+	return 1
+}
+
+// scaleImage returns img as non-premultiplied pixels, scaled down to fit size x size (unless size is zero).
+// Each pixel is the average of the pixels of img it covers, weighted by their alpha,
+// so that the transparent ones don't darken the edges.
+func scaleImage(img image.Image, size int) *image.NRGBA {
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := fitSize(sw, sh, size)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, (y+1)*sh/dh
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, (x+1)*sw/dw
+			var r, g, bl, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[4*sx : 4*sx+4]
+					r, g, bl, a = r+uint64(p[0]), g+uint64(p[1]), bl+uint64(p[2]), a+uint64(p[3])
+					count++
+				}
+			}
+			d := dst.Pix[y*dst.Stride+4*x : y*dst.Stride+4*x+4]
+			if a == 0 {
+				continue
+			}
+			// un-premultiply the sums, then average the alpha
+			d[0] = uint8((r*0xff + a/2) / a)
+			d[1] = uint8((g*0xff + a/2) / a)
+			d[2] = uint8((bl*0xff + a/2) / a)
+			d[3] = uint8((a + count/2) / count)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,122 @@
+package toast
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestFitSize(t *testing.T) {
+	for _, tt := range []struct {
+		width, height, size int
+		expected            [2]int
+	}{
+		{64, 64, 256, [2]int{64, 64}},
+		{512, 512, 256, [2]int{256, 256}},
+		{1024, 512, 256, [2]int{256, 128}},
+		{300, 1000, 256, [2]int{77, 256}},
+		{5000, 1, 256, [2]int{256, 1}},
+		{5000, 3000, 0, [2]int{5000, 3000}},
+	} {
+		if w, h := fitSize(tt.width, tt.height, tt.size); [2]int{w, h} != tt.expected {
+			t.Errorf("%dx%d in %d: expected %v, got %dx%d", tt.width, tt.height, tt.size, tt.expected, w, h)
+		}
+	}
+}
+
+func TestScaleImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(10, 10, 14, 12))
+	for x := 10; x < 14; x++ {
+		img.Set(x, 10, color.NRGBA{R: 0xff, A: 0xff})
+		img.Set(x, 11, color.NRGBA{B: 0xff, A: 0xff})
+	}
+	// half transparent: the color of the opaque pixels, half their alpha
+	img.Set(12, 10, color.NRGBA{})
+	img.Set(13, 11, color.NRGBA{G: 0xff})
+
+	scaled := scaleImage(img, 2)
+	if scaled.Rect != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("unexpected bounds %v", scaled.Rect)
+	}
+	for x, expected := range []color.NRGBA{{R: 0x80, B: 0x80, A: 0xff}, {R: 0x80, B: 0x80, A: 0x80}} {
+		if c := scaled.NRGBAAt(x, 0); c != expected {
+			t.Errorf("%d: expected %v, got %v", x, expected, c)
+		}
+	}
+
+	if unscaled := scaleImage(img, 0); unscaled.Rect.Dx() != 4 || unscaled.NRGBAAt(0, 1) != (color.NRGBA{B: 0xff, A: 0xff}) {
+		t.Errorf("expected the image as is, got %v", unscaled.Rect)
+	}
+}
+
+func TestFitIcon(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	encoded := map[string][]byte{}
+	for format, encode := range map[string]func(*bytes.Buffer) error{
+		"png":  func(buf *bytes.Buffer) error { return png.Encode(buf, img) },
+		"jpeg": func(buf *bytes.Buffer) error { return jpeg.Encode(buf, img, nil) },
+		"gif":  func(buf *bytes.Buffer) error { return gif.Encode(buf, img, nil) },
+	} {
+		var buf bytes.Buffer
+		checkErr(t, encode(&buf))
+		encoded[format] = buf.Bytes()
+	}
+
+	for format, raw := range encoded {
+		fitted, err := fitIcon(raw, 100)
+		checkErr(t, err)
+		config, name, err := image.DecodeConfig(bytes.NewReader(fitted))
+		checkErr(t, err)
+		if name != "png" || config.Width != 100 || config.Height != 50 {
+			t.Errorf("%s: expected a 100x50 png, got a %dx%d %s", format, config.Width, config.Height, name)
+		}
+
+		// already fitting
+		if fitted, err = fitIcon(raw, 400); err != nil || !bytes.Equal(fitted, raw) {
+			t.Errorf("%s: expected the image as is, got %v", format, err)
+		}
+		if fitted, err = fitIcon(raw, 0); err != nil || !bytes.Equal(fitted, raw) {
+			t.Errorf("%s: expected the image as is without a limit, got %v", format, err)
+		}
+	}
+
+	// the formats not decoded are left to the backend
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="4096" height="4096"/>`)
+	if fitted, err := fitIcon(svg, 100); err != nil || !bytes.Equal(fitted, svg) {
+		t.Errorf("expected the svg as is, got %v", err)
+	}
+
+	if pixels, err := decodeImage(encoded["gif"], 256); err != nil || pixels.Rect.Dx() != 256 || pixels.Rect.Dy() != 128 {
+		t.Errorf("expected 256x128 pixels, got %v (%v)", pixels, err)
+	}
+	if pixels, err := decodeImage(svg, 256); pixels != nil || err != nil {
+		t.Errorf("expected the svg not to be decoded, got %v", err)
+	}
+}
+
+func TestFitIcon_tooLarge(t *testing.T) {
+	// the header of a 65535x65535 gif, rejected before its pixels are read
+	huge := []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00")
+	config, _, err := image.DecodeConfig(bytes.NewReader(huge))
+	checkErr(t, err)
+	if config.Width != 65535 || config.Height != 65535 {
+		t.Fatalf("unexpected config %+v", config)
+	}
+	if _, err = fitIcon(huge, 256); err == nil {
+		t.Error("expected an error for an image over the limit")
+	}
+	if _, err = decodeImage(huge, 256); err == nil {
+		t.Error("expected an error for an image over the limit")
+	}
+
+	// as large as it gets
+	var buf bytes.Buffer
+	checkErr(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, maxImagePixels/2048, 2048))))
+	if pixels, err := decodeImage(buf.Bytes(), 256); err != nil || pixels.Rect.Dx() != 256 {
+		t.Errorf("expected the image at the limit to be decoded, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package toast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBackend is the name of the platform's default backend, Push uses it until Use is called.
+const DefaultBackend = "default"
+
+func init() {
+	Register(DefaultBackend, defaultNotifier)
+}
+
+// Notifier is a notification backend.
+//
+// Backends are added with Register and selected with Use.
+// Notify should return once ctx is done.
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// NotifierFunc adapts an ordinary function to a Notifier.
+type NotifierFunc func(ctx context.Context, n *Notification) error
+
+func (fn NotifierFunc) Notify(ctx context.Context, n *Notification) error {
+	return fn(ctx, n)
+}
+
+// NotifierFactory creates the Notifier registered under a name.
+type NotifierFactory func() (Notifier, error)
+
+var (
+	_mu        sync.RWMutex
+	_factories = make(map[string]NotifierFactory)
+	_current   Notifier
+)
+
+// Register makes a backend available by the provided name.
+// If Register is called twice with the same name or if factory is nil, it panics.
+func Register(name string, factory NotifierFactory) {
+	_mu.Lock()
+	defer _mu.Unlock()
+	if factory == nil {
+		panic("toast: Register factory is nil")
+	}
+	if _, dup := _factories[name]; dup {
+		panic("toast: Register called twice for backend " + name)
+	}
+	_factories[name] = factory
+}
+
+// Backends returns a sorted list of the names of the registered backends.
+func Backends() []string {
+	_mu.RLock()
+	defer _mu.RUnlock()
+	names := make([]string, 0, len(_factories))
+	for name := range _factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Use selects the registered backend used by Push.
+// The previous backend stays selected if the factory returns an error.
+func Use(name string) error {
+	nf, err := newNotifier(name)
+	if err != nil {
+		return err
+	}
+	_mu.Lock()
+	defer _mu.Unlock()
+	_current = nf
+	return nil
+}
+
+func newNotifier(name string) (Notifier, error) {
+	_mu.RLock()
+	factory, ok := _factories[name]
+	_mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("toast: unknown backend %q (forgotten import?)", name)
+	}
+	nf, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("toast: backend %q: %w", name, err)
+	}
+	return nf, nil
+}
+
+// current returns the selected Notifier, creating the platform's default backend on first use.
+func current() (Notifier, error) {
+	_mu.RLock()
+	nf := _current
+	_mu.RUnlock()
+	if nf != nil {
+		return nf, nil
+	}
+
+	nf, err := newNotifier(DefaultBackend)
+	if err != nil {
+		return nil, err
+	}
+	_mu.Lock()
+	defer _mu.Unlock()
+	if _current == nil {
+		_current = nf
+	}
+	return _current, nil
+}
+
+// Chain returns a Notifier that tries each notifier in order until one succeeds.
+// If all of them fail the returned error is a ChainError holding every failure,
+// if ctx is done before that it is ctx.Err().
+func Chain(notifiers ...Notifier) Notifier {
+	return chain(notifiers)
+}
+
+type chain []Notifier
+
+// WritesIcon is true as every backend of the chain is handed the notification by notify.
+func (chain) WritesIcon() bool { return true }
+
+func (c chain) Notify(ctx context.Context, n *Notification) error {
+	if len(c) == 0 {
+		return errors.New("toast: empty chain")
+	}
+	errs := make(ChainError, 0, len(c))
+	for _, nf := range c {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := notify(ctx, nf, n)
+		if err == nil {
+			delivered(n, nf)
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ChainError is returned by a Chain when every Notifier failed, in the order they were tried.
+type ChainError []error
+
+func (e ChainError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "toast: all backends failed: " + strings.Join(msgs, "; ")
+}
+
+func (e ChainError) Unwrap() []error {
+	return e
+}
+
+// Is reports whether any of the failures matches target,
+// errors.Is only follows Unwrap() []error from Go 1.20 on.
+func (e ChainError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the failures matching target, like Is.
+func (e ChainError) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Backend returns a Notifier creating the backend registered by name each time it is used,
+// so that a backend which isn't available is one more failure in a Chain:
+//
+//	toast.Chain(toast.Backend("dbus"), toast.Backend("notify-send"), toast.Backend("terminal"), toast.Backend("stderr"))
+func Backend(name string) Notifier {
+	return registered(name)
+}
+
+type registered string
+
+// Notify creates the backend once, and has it display n as notify would.
+func (name registered) Notify(ctx context.Context, n *Notification) error {
+	nf, err := newNotifier(string(name))
+	if err != nil {
+		return err
+	}
+	if err = notify(ctx, nf, n); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	delivered(n, nf)
+	return nil
+}
@@ -0,0 +1,176 @@
+package toast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	var got []*Notification
+	register(t, "test-register", func() (Notifier, error) {
+		return NotifierFunc(func(_ context.Context, n *Notification) error {
+			got = append(got, n)
+			return nil
+		}), nil
+	})
+	t.Cleanup(func() { checkErr(t, Use(DefaultBackend)) })
+
+	found := false
+	for _, name := range Backends() {
+		found = found || name == "test-register"
+	}
+	if !found {
+		t.Fatalf("expected test-register in %v", Backends())
+	}
+
+	checkErr(t, Use("test-register"))
+	checkErr(t, Push("test_message", WithTitle("test_title")))
+	if len(got) != 1 || got[0].Title != "test_title" || got[0].Message != "test_message" {
+		t.Fatalf("unexpected notifications: %+v", got)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected Register to panic on a duplicate name")
+			}
+		}()
+		Register("test-register", func() (Notifier, error) { return nil, nil })
+	}()
+}
+
+func TestUse(t *testing.T) {
+	errFactory := errors.New("factory failed")
+	register(t, "test-use-failing", func() (Notifier, error) { return nil, errFactory })
+	register(t, "test-use", func() (Notifier, error) {
+		return NotifierFunc(func(context.Context, *Notification) error { return nil }), nil
+	})
+	t.Cleanup(func() { checkErr(t, Use(DefaultBackend)) })
+
+	if err := Use("test-use-unknown"); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+	checkErr(t, Use("test-use"))
+	if err := Use("test-use-failing"); !errors.Is(err, errFactory) {
+		t.Errorf("expected %v, got %v", errFactory, err)
+	}
+	// the previous backend stays selected
+	checkErr(t, Push("test_message"))
+}
+
+// register is Register, undone at the end of the test.
+func register(t *testing.T, name string, factory NotifierFactory) {
+	Register(name, factory)
+	t.Cleanup(func() {
+		_mu.Lock()
+		defer _mu.Unlock()
+		delete(_factories, name)
+	})
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	err1, err2 := errors.New("first failed"), errors.New("second failed")
+	var calls []string
+	notifier := func(name string, err error) Notifier {
+		return NotifierFunc(func(context.Context, *Notification) error {
+			calls = append(calls, name)
+			return err
+		})
+	}
+
+	checkErr(t, Chain(notifier("1", err1), notifier("2", nil), notifier("3", nil)).Notify(ctx, &Notification{}))
+	if len(calls) != 2 || calls[1] != "2" {
+		t.Errorf("expected the chain to stop at the first success, got %v", calls)
+	}
+
+	err := Chain(notifier("1", err1), notifier("2", err2)).Notify(ctx, &Notification{})
+	var chainErr ChainError
+	if !errors.As(err, &chainErr) || len(chainErr) != 2 {
+		t.Fatalf("expected a ChainError with 2 errors, got %v", err)
+	}
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Errorf("expected %v to wrap both failures", err)
+	}
+	// without Unwrap() []error, before Go 1.20
+	if !chainErr.Is(err2) || chainErr.Is(errors.New("test_other")) {
+		t.Errorf("expected %v to match its failures only", err)
+	}
+	unsupported := &UnsupportedOptionsError{Options: []string{"WithSubtitle"}}
+	var target *UnsupportedOptionsError
+	if !(ChainError{err1, fmt.Errorf("2: %w", unsupported)}).As(&target) || target != unsupported {
+		t.Errorf("expected the UnsupportedOptionsError, got %v", target)
+	}
+
+	if err = Chain().Notify(ctx, &Notification{}); err == nil {
+		t.Error("expected an error for an empty chain")
+	}
+	if err = Chain(registered("test-chain-unknown")).Notify(ctx, &Notification{}); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
+
+func TestBackend_once(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	created := 0
+	register(t, "test-backend-once", func() (Notifier, error) {
+		created++
+		return honoring{"WithAppID", "WithIconRaw"}, nil
+	})
+
+	n := NewNotification("test_message", WithAppID("test_app"), WithIconRaw([]byte("\x89PNG\r\n\x1a\ntest")), WithStrict())
+	checkErr(t, Chain(Backend("test-backend-once")).Notify(context.Background(), n))
+	if created != 1 {
+		t.Errorf("expected the backend to be created once, got %d", created)
+	}
+	if _, ok := n.sender.(honoring); !ok || len(n.Icon) == 0 {
+		t.Errorf("expected the backend to display the notification with its icon, got %v %q", n.sender, n.Icon)
+	}
+
+	WithSubtitle("test_subtitle")(n)
+	if err := Backend("test-backend-once").Notify(context.Background(), n); !errors.Is(err, ErrOptionUnsupported) || created != 2 {
+		t.Errorf("expected %v from a second backend, got %v (%d)", ErrOptionUnsupported, err, created)
+	}
+}
+
+func TestChain_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	nf := NotifierFunc(func(context.Context, *Notification) error {
+		calls++
+		cancel()
+		return errors.New("failed")
+	})
+	if err := Chain(nf, nf).Notify(ctx, &Notification{}); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the chain to stop once canceled, got %d calls", calls)
+	}
+}
+
+type capsNotifier Caps
+
+func (capsNotifier) Notify(context.Context, *Notification) error { return nil }
+
+func (c capsNotifier) Capabilities(context.Context) (Caps, error) { return Caps(c), nil }
+
+func TestChain_capabilities(t *testing.T) {
+	register(t, "test-caps", func() (Notifier, error) { return capsNotifier{Sounds: true}, nil })
+	register(t, "test-caps-failing", func() (Notifier, error) { return nil, errors.New("factory failed") })
+	silent := NotifierFunc(func(context.Context, *Notification) error { return nil })
+
+	nf := Chain(Backend("test-caps-failing"), silent, Backend("test-caps"), capsNotifier{Actions: true})
+	caps, err := nf.(CapsReporter).Capabilities(context.Background())
+	checkErr(t, err)
+	if caps != (Caps{Sounds: true}) {
+		t.Errorf("expected the capabilities of test-caps, got %+v", caps)
+	}
+
+	if _, err = Chain(silent).(CapsReporter).Capabilities(context.Background()); err == nil {
+		t.Error("expected an error without a backend reporting its capabilities")
+	}
+}
@@ -0,0 +1,549 @@
+package toast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// Every option is available on every platform, a backend unable to honor one ignores it
+// unless WithStrict is given.
+
+// ErrOptionUnsupported is matched by the UnsupportedOptionsError returned with WithStrict.
+var ErrOptionUnsupported = errors.New("toast: option not supported by the backend")
+
+// UnsupportedOptionsError lists the options the backend would have dropped.
+type UnsupportedOptionsError struct {
+	Options []string
+}
+
+func (e *UnsupportedOptionsError) Error() string {
+	return ErrOptionUnsupported.Error() + ": " + strings.Join(e.Options, ", ")
+}
+
+func (e *UnsupportedOptionsError) Is(target error) bool {
+	return target == ErrOptionUnsupported
+}
+
+// WithStrict
+//
+// Fails with an UnsupportedOptionsError, instead of displaying the notification without them,
+// if the backend can't honor some of the options. A Chain moves on to the next backend.
+func WithStrict() NotificationOption {
+	return func(n *Notification) {
+		n.strict = true
+	}
+}
+
+// WithAppID
+//
+// The name of your app: the freedesktop app_name, or the app shown in Windows 10's Action Centre,
+// so make it something readable for your users. It can contain spaces,
+// however special characters (eg. é) are not supported on Windows.
+func WithAppID(appID string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithAppID")
+		n.AppID = appID
+	}
+}
+
+// WithIcon
+//
+// An optional path to an image on the OS to display to the left of the title & message,
+// a freedesktop icon name on Linux, the URL of the image in the browser.
+func WithIcon(pathIcon string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithIcon")
+		n.Icon = pathIcon
+		n._iconRaw, n._iconLoad = nil, nil
+	}
+}
+
+// WithIconRaw
+//
+// Like WithIcon, with the content of a PNG, JPEG, GIF, BMP, ICO, WebP or SVG image.
+// The image is written when the notification is pushed, to a cache directory where the files
+// not used for a day are removed. Freedesktop servers get the pixels in the image-data hint instead,
+// browsers a data: URL. PNG, JPEG and GIF images larger than the platform displays are scaled down.
+func WithIconRaw(raw []byte) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithIconRaw")
+		n.Icon = ""
+		n._iconRaw, n._iconLoad = append([]byte(nil), raw...), nil
+	}
+}
+
+// WithIconImage
+//
+// Like WithIconRaw, with the image encoded to PNG.
+func WithIconImage(img image.Image) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithIconImage")
+		n.Icon = ""
+		n._iconRaw, n._iconLoad = nil, func() ([]byte, error) {
+			var buf bytes.Buffer
+			err := png.Encode(&buf, img)
+			return buf.Bytes(), err
+		}
+	}
+}
+
+// WithIconFS
+//
+// Like WithIconRaw, with the file name of fsys (like an embed.FS). The file is read when the notification is pushed,
+// which fails if it can't be.
+func WithIconFS(fsys fs.FS, name string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithIconFS")
+		n.Icon = ""
+		n._iconRaw, n._iconLoad = nil, func() ([]byte, error) {
+			return fs.ReadFile(fsys, name)
+		}
+	}
+}
+
+// WithIconReader
+//
+// Like WithIconRaw, with the content of r. It's read when the notification is first pushed,
+// which fails if it can't be.
+func WithIconReader(r io.Reader) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithIconReader")
+		n.Icon = ""
+		n._iconRaw, n._iconLoad = nil, func() ([]byte, error) {
+			return io.ReadAll(r)
+		}
+	}
+}
+
+// WithImage
+//
+// The URL of an image to be displayed as part of the notification,
+// a path on Windows and Linux (the freedesktop image-path hint).
+func WithImage(urlImage string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithImage")
+		n.Image = urlImage
+	}
+}
+
+// WithInlineImage
+//
+// Like WithImage, the name of the Windows toast element: an image displayed below the text.
+func WithInlineImage(pathImage string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithInlineImage")
+		n.Image = pathImage
+	}
+}
+
+// WithHeroImage
+//
+// An optional path to an image displayed above the text, across the Windows toast.
+func WithHeroImage(pathImage string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithHeroImage")
+		n.HeroImage = pathImage
+	}
+}
+
+// WithIconCrop
+//
+// How the icon is cropped on Windows, Circle for a profile picture.
+func WithIconCrop(crop IconCrop) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithIconCrop")
+		n.IconCrop = crop
+	}
+}
+
+// WithAttribution
+//
+// The source of the content, displayed below the other text of the Windows toast in a smaller font,
+// like "Via SMS".
+func WithAttribution(text string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithAttribution")
+		n.Attribution = text
+	}
+}
+
+// WithSubtitle
+//
+// The subtitle of the notification, displayed as a line between the title & message on Windows.
+func WithSubtitle(subtitle string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithSubtitle")
+		n.Subtitle = subtitle
+	}
+}
+
+// WithObjectiveC
+//
+// Displays the notification through NSUserNotificationCenter (macOS, requires cgo)
+// instead of osascript.
+func WithObjectiveC(b ...bool) NotificationOption {
+	if len(b) == 0 {
+		b = []bool{true}
+	}
+	return func(n *Notification) {
+		n.use("WithObjectiveC")
+		n._useObjC = b[0]
+	}
+}
+
+// WithUrgency
+//
+// The urgency level of the notification (low/normal/critical): the freedesktop urgency hint,
+// the "urgent" scenario on Windows ("alarm" if the toast has buttons), the interruption level on macOS
+// (Objective-C only) and requireInteraction in the browser for Critical.
+func WithUrgency(u Urgency) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithUrgency")
+		n.Urgency = u
+	}
+}
+
+// WithTimeout
+//
+// How long the notification should show up for, the server decides when it's zero:
+// the freedesktop expire_timeout, the duration and ExpirationTime of the toast on Windows,
+// a timer closing the notification in the browser.
+func WithTimeout(d time.Duration) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithTimeout")
+		n.Timeout = d
+		n.Persistent = false
+	}
+}
+
+// WithPersistent
+//
+// The notification shows up until the user closes it: a zero freedesktop expire_timeout,
+// a long toast on Windows, requireInteraction in the browser.
+func WithPersistent() NotificationOption {
+	return func(n *Notification) {
+		n.use("WithPersistent")
+		n.Persistent = true
+		n.Timeout = 0
+	}
+}
+
+// WithActivationType
+//
+// The type of notification level action (like Action)
+func WithActivationType(activationType string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithActivationType")
+		n.ActivationType = activationType
+	}
+}
+
+// WithActivationArguments
+//
+// // The activation/action arguments (invoked when the user clicks the notification)
+func WithActivationArguments(activationArguments string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithActivationArguments")
+		n.ActivationArguments = activationArguments
+	}
+}
+
+// WithProtocolAction
+//
+// Defines an actionable button.
+// See https://msdn.microsoft.com/en-us/windows/uwp/controls-and-patterns/tiles-and-notifications-adaptive-interactive-toasts for more info.
+//
+// Protocol type action buttons launch an URI, use WithAction to receive feedback from the user's choice instead.
+// Examples of protocol type action buttons include: "bingmaps:?q=sushi" to open up Windows 10's
+// maps app with a pre-populated search field set to "sushi".
+//
+//	WithProtocolAction("Open Maps", "bingmaps:?q=sushi")
+func WithProtocolAction(label string, arguments ...string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithProtocolAction")
+		if len(n.Actions) == maxActions {
+			return
+		}
+		if len(arguments) == 0 {
+			arguments = []string{""}
+		}
+		n.Actions = append(n.Actions, Action{
+			Type:      "protocol",
+			Label:     label,
+			Arguments: arguments[0],
+		})
+	}
+}
+
+// WithProgress
+//
+// Displays a progress bar: value goes from 0.0 to 1.0, a negative value is indeterminate.
+// valueString replaces the percentage displayed (like "3/10 files") and status is displayed below the bar.
+// The freedesktop value hint only has the value. Handle.SetProgress updates the bar in place.
+func WithProgress(title string, value float64, valueString, status string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithProgress")
+		n.Progress = &Progress{
+			Title:       title,
+			Value:       value,
+			ValueString: valueString,
+			Status:      status,
+		}
+	}
+}
+
+// Progress is the progress bar of a notification, see WithProgress.
+type Progress struct {
+	Title       string  `json:"title,omitempty" yaml:"title,omitempty"`
+	Value       float64 `json:"value" yaml:"value"`
+	ValueString string  `json:"value_string,omitempty" yaml:"value_string,omitempty"`
+	Status      string  `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// WithAudioLoop
+//
+// Whether to loop the audio (default false), of a long toast only on Windows (see WithScenario)
+func WithAudioLoop(b bool) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithAudioLoop")
+		n.Loop = b
+	}
+}
+
+// WithDuration
+//
+// How long the notification should show up for (short/long)
+func WithDuration(nd NotificationDuration) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithDuration")
+		n.Duration = nd
+	}
+}
+
+func WithLongDuration() NotificationOption {
+	return WithDuration(Long)
+}
+
+func WithShortDuration() NotificationOption {
+	return WithDuration(Short)
+}
+
+// WithTextDirection
+//
+// The text direction of the notification
+func WithTextDirection(dir TextDirection) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithTextDirection")
+		n.TextDirection = dir
+	}
+}
+
+// WithLang
+//
+// The language code of the notification
+func WithLang(lang string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithLang")
+		n.Lang = lang
+	}
+}
+
+// WithNotificationID
+//
+// The ID of the notification (if any), the notification replaces the one displayed with the same ID.
+func WithNotificationID(tag string) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithNotificationID")
+		n.ID = tag
+	}
+}
+
+// WithRenotify
+//
+// Specifies whether the user should be notified after a new notification replaces an old one
+func WithRenotify(b bool) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithRenotify")
+		n.Renotify = b
+	}
+}
+
+// WithRequireInteraction
+//
+// indicating that a notification should remain active until the user clicks or dismisses it,
+// rather than closing automatically.
+func WithRequireInteraction(b bool) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithRequireInteraction")
+		n.RequireInteraction = b
+	}
+}
+
+// WithSilent
+//
+// Specifies whether the notification should be silent — i.e., no sounds or vibrations should be issued,
+// regardless of the device settings.
+func WithSilent(b bool) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithSilent")
+		n.Silent = b
+	}
+}
+
+// WithTimestamp
+//
+// Specifies the time at which a notification is created or applicable (past, present, or future).
+func WithTimestamp(t time.Time) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithTimestamp")
+		n.Timestamp = t
+	}
+}
+
+// WithVibrate
+//
+// Specifies a vibration pattern for devices with vibration hardware to emit.
+func WithVibrate(v []int) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithVibrate")
+		n.Vibrate = v
+	}
+}
+
+// WithOnClick
+//
+// A handler for the click event.
+// It is triggered each time the user clicks on the notification.
+func WithOnClick(fn func(event interface{})) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithOnClick")
+		n._onClick = fn
+	}
+}
+
+// WithOnShow
+//
+// A handler for the show event.
+// It is triggered when the notification is displayed.
+func WithOnShow(fn func()) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithOnShow")
+		n._onShow = fn
+	}
+}
+
+// WithOnClose
+//
+// A handler for the close event.
+// It is triggered when the user closes the notification.
+func WithOnClose(fn func()) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithOnClose")
+		n._onClose = fn
+	}
+}
+
+// WithOnError
+//
+// A handler for the error event.
+// It is triggered each time the notification encounters an error.
+func WithOnError(fn func()) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithOnError")
+		n._onError = fn
+	}
+}
+
+type Urgency string
+
+const (
+	Low      Urgency = "low"
+	Normal   Urgency = "normal"
+	Critical Urgency = "critical"
+)
+
+type IconCrop string
+
+const (
+	Circle IconCrop = "circle"
+)
+
+type NotificationDuration string
+
+const (
+	Short NotificationDuration = "short"
+	Long  NotificationDuration = "long"
+)
+
+type TextDirection string
+
+const (
+	// Auto adopts the browser's language setting behavior (the default.)
+	Auto TextDirection = "auto"
+	// LTR left to right
+	LTR TextDirection = "ltr"
+	// RTL right to left
+	RTL TextDirection = "rtl"
+)
+
+// use records that the option was given, so that WithStrict is able to tell which ones are dropped.
+func (n *Notification) use(option string) {
+	if !n.uses(option) {
+		n.options = append(n.options, option)
+	}
+}
+
+// uses reports whether the option was given to n.
+func (n *Notification) uses(option string) bool {
+	for _, name := range n.options {
+		if name == option {
+			return true
+		}
+	}
+	return false
+}
+
+// dropped returns the options given to n which aren't in supported, in the order they were given.
+func (n *Notification) dropped(supported ...string) (names []string) {
+	for _, name := range n.options {
+		found := false
+		for _, s := range supported {
+			found = found || s == name
+		}
+		if !found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// optionDropper is implemented by the backends which know the options they honor.
+type optionDropper interface {
+	droppedOptions(ctx context.Context, n *Notification) []string
+}
+
+// notify has nf display n, unless WithStrict was given and nf would drop some of the options.
+func notify(ctx context.Context, nf Notifier, n *Notification) error {
+	if name, ok := nf.(registered); ok {
+		// the checks below are done by the backend created by Notify
+		return name.Notify(ctx, n)
+	}
+	if d, ok := nf.(optionDropper); ok && n.strict {
+		if names := d.droppedOptions(ctx, n); len(names) != 0 {
+			return &UnsupportedOptionsError{Options: names}
+		}
+	}
+	if needsIconFile(ctx, nf, n) {
+		if err := materializeIcon(n, maxIconSize(nf)); err != nil {
+			return err
+		}
+	}
+	return nf.Notify(ctx, n)
+}
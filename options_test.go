@@ -0,0 +1,68 @@
+package toast
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// every option compiles on every platform
+var _ = []NotificationOption{
+	WithTitle(""), WithMessage(""), WithAudio(Default), WithAction("", "", nil), WithStrict(),
+	WithAppID(""), WithIcon(""), WithIconRaw(nil), WithIconImage(nil), WithIconFS(nil, ""), WithIconReader(nil),
+	WithImage(""), WithInlineImage(""), WithHeroImage(""), WithIconCrop(Circle),
+	WithAttribution(""), WithSubtitle(""), WithObjectiveC(),
+	WithUrgency(Critical), WithTimeout(time.Second), WithPersistent(), WithProgress("", 0, "", ""),
+	WithTextInput("", ""), WithSelection("", nil), WithScenario(ScenarioAlarm),
+	WithActivationType(""), WithActivationArguments(""), WithProtocolAction(""), WithAudioLoop(true),
+	WithDuration(Long), WithLongDuration(), WithShortDuration(),
+	WithTextDirection(RTL), WithLang(""), WithNotificationID(""), WithRenotify(true), WithRequireInteraction(true),
+	WithSilent(true), WithTimestamp(time.Time{}), WithVibrate(nil),
+	WithOnClick(nil), WithOnShow(nil), WithOnClose(nil), WithOnError(nil),
+}
+
+// honoring is a backend honoring the given options only.
+type honoring []string
+
+func (h honoring) Notify(context.Context, *Notification) error { return nil }
+
+func (h honoring) droppedOptions(_ context.Context, n *Notification) []string { return n.dropped(h...) }
+
+func TestWithStrict(t *testing.T) {
+	n := &Notification{}
+	for _, fn := range []NotificationOption{WithSubtitle("a"), WithAppID("b"), WithSubtitle("c"), WithLang("d")} {
+		fn(n)
+	}
+	checkErr(t, notify(context.Background(), honoring{"WithAppID"}, n))
+
+	WithStrict()(n)
+	err := notify(context.Background(), honoring{"WithAppID"}, n)
+	var unsupported *UnsupportedOptionsError
+	if !errors.Is(err, ErrOptionUnsupported) || !errors.As(err, &unsupported) {
+		t.Fatalf("expected %v, got %v", ErrOptionUnsupported, err)
+	}
+	if got := unsupported.Options; len(got) != 2 || got[0] != "WithSubtitle" || got[1] != "WithLang" {
+		t.Errorf("expected WithSubtitle and WithLang to be dropped, got %q", got)
+	}
+
+	// neither replacing a notification nor silencing it is taken for granted
+	for _, opt := range []NotificationOption{WithNotificationID("test_id"), WithSilent(true)} {
+		n := &Notification{}
+		WithStrict()(n)
+		opt(n)
+		if err := notify(context.Background(), line{w: io.Discard}, n); !errors.Is(err, ErrOptionUnsupported) {
+			t.Errorf("expected %v, got %v", ErrOptionUnsupported, err)
+		}
+	}
+
+	// a backend which doesn't tell is trusted
+	checkErr(t, notify(context.Background(), NotifierFunc(func(context.Context, *Notification) error { return nil }), n))
+
+	nf := Chain(honoring{"WithAppID"}, honoring{"WithAppID", "WithSubtitle", "WithLang"})
+	checkErr(t, nf.Notify(context.Background(), n))
+	if h, ok := n.sender.(honoring); !ok || len(h) != 3 {
+		t.Errorf("expected the second backend to display the notification, got %v", n.sender)
+	}
+}
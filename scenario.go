@@ -0,0 +1,87 @@
+package toast
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Scenario is how Windows displays a toast, see WithScenario.
+type Scenario string
+
+const (
+	// ScenarioReminder keeps the toast on screen until the user acts on it
+	ScenarioReminder Scenario = "reminder"
+
+	// ScenarioAlarm keeps the toast on screen and loops its audio, LoopingAlarm by default
+	ScenarioAlarm Scenario = "alarm"
+
+	// ScenarioIncomingCall is like ScenarioAlarm with the buttons of a call, LoopingCall by default
+	ScenarioIncomingCall Scenario = "incomingCall"
+
+	// ScenarioUrgent displays the toast through focus assist (Windows 11)
+	ScenarioUrgent Scenario = "urgent"
+)
+
+// ErrInvalidScenario is returned by the Windows backend for a scenario it wouldn't honor.
+var ErrInvalidScenario = errors.New("toast: invalid scenario")
+
+// WithScenario
+//
+// Sets the Windows scenario of the toast, overriding the one of WithUrgency.
+// Reminders, alarms and incoming calls need at least one action, else Windows displays a regular toast:
+// the notification is rejected with ErrInvalidScenario instead. So is a looping audio, unless the toast is long
+// or one of these three, which are made long.
+func WithScenario(s Scenario) NotificationOption {
+	return func(n *Notification) {
+		n.use("WithScenario")
+		n.Scenario = s
+	}
+}
+
+// validateScenario returns an error wrapping ErrInvalidScenario if Windows wouldn't honor the scenario of n.
+func validateScenario(n *Notification) error {
+	s := n.scenario()
+	switch s {
+	case "", ScenarioUrgent:
+	case ScenarioReminder, ScenarioAlarm, ScenarioIncomingCall:
+		if len(n.buttons()) == 0 {
+			return fmt.Errorf("%w: %s needs at least one action", ErrInvalidScenario, s)
+		}
+	default:
+		return fmt.Errorf("%w: unknown scenario %q", ErrInvalidScenario, s)
+	}
+	// the schema loops the audio of long toasts only, whatever the scenario
+	if n.Loop && toastDuration(n) != Long {
+		return fmt.Errorf("%w: the audio of a short toast doesn't loop", ErrInvalidScenario)
+	}
+	return nil
+}
+
+// scenario returns the scenario of the toast, the one of the urgency of n unless it's given WithScenario.
+func (n *Notification) scenario() Scenario {
+	if len(n.Scenario) != 0 {
+		return n.Scenario
+	}
+	return Scenario(n.Urgency.windowsScenario(len(n.buttons()) != 0))
+}
+
+// scenarioAudios are the audios of the scenarios looping one unless given WithAudio.
+var scenarioAudios = map[Scenario]Audio{
+	ScenarioAlarm:        LoopingAlarm,
+	ScenarioIncomingCall: LoopingCall,
+}
+
+// toastAudio returns the audio of the toast and whether it loops, the looping audio of the scenario
+// unless it's given WithAudio or WithSilent.
+func toastAudio(n *Notification) (Audio, bool) {
+	if audio, ok := scenarioAudios[n.scenario()]; ok && !n.Silent && !n.uses("WithAudio") &&
+		(len(n.Audio) == 0 || n.Audio == Silent) {
+		return audio, true
+	}
+	return n.Audio, n.Loop
+}
+
+// keepsOnScreen reports whether the toast stays on screen until the user acts on it.
+func (s Scenario) keepsOnScreen() bool {
+	return s == ScenarioReminder || s == ScenarioAlarm || s == ScenarioIncomingCall
+}
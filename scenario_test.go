@@ -0,0 +1,82 @@
+package toast
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/electricbubble/go-toast/toastxml"
+)
+
+func TestValidateScenario(t *testing.T) {
+	snooze := WithAction("snooze", "Snooze", nil)
+	for _, tt := range []struct {
+		name  string
+		opts  []NotificationOption
+		valid bool
+	}{
+		{"none", nil, true},
+		{"urgent", []NotificationOption{WithScenario(ScenarioUrgent)}, true},
+		{"reminder", []NotificationOption{WithScenario(ScenarioReminder), snooze}, true},
+		{"reminder without action", []NotificationOption{WithScenario(ScenarioReminder)}, false},
+		{"default action only", []NotificationOption{WithScenario(ScenarioAlarm), WithAction(DefaultAction, "", func(ActionEvent) {})}, false},
+		{"unknown", []NotificationOption{WithScenario("snooze"), snooze}, false},
+		{"alarm loop", []NotificationOption{WithScenario(ScenarioAlarm), snooze, WithAudio(LoopingAlarm2), WithAudioLoop(true)}, true},
+		{"incoming call loop", []NotificationOption{WithScenario(ScenarioIncomingCall), snooze, WithAudioLoop(true)}, true},
+		{"alarm looping audio", []NotificationOption{WithScenario(ScenarioAlarm), snooze, WithAudio(LoopingAlarm3), WithTimeout(time.Second)}, true},
+		{"short reminder loop", []NotificationOption{WithScenario(ScenarioReminder), snooze, WithAudioLoop(true)}, true},
+		{"long reminder loop", []NotificationOption{WithScenario(ScenarioReminder), snooze, WithAudioLoop(true), WithTimeout(time.Minute)}, true},
+		{"short loop without scenario", []NotificationOption{WithAudio(Default), WithAudioLoop(true)}, false},
+		{"long loop without scenario", []NotificationOption{WithAudio(Default), WithAudioLoop(true), WithDuration(Long)}, true},
+		{"critical with an action loop", []NotificationOption{WithUrgency(Critical), snooze, WithAudioLoop(true)}, true},
+		{"critical loop", []NotificationOption{WithUrgency(Critical), WithAudioLoop(true)}, false},
+		{"looping audio played once", []NotificationOption{WithAudio(LoopingCall2)}, true},
+		{"short urgent loop", []NotificationOption{WithScenario(ScenarioUrgent), WithAudioLoop(true), WithTimeout(time.Second)}, false},
+		{"persistent urgent loop", []NotificationOption{WithScenario(ScenarioUrgent), WithAudioLoop(true), WithPersistent()}, true},
+	} {
+		n := &Notification{}
+		for _, fn := range tt.opts {
+			fn(n)
+		}
+		err := validateScenario(n)
+		if err == nil && n.Loop && toastDuration(n) != Long {
+			t.Errorf("%s: expected a long toast for the looping audio", tt.name)
+		}
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidScenario) {
+			t.Errorf("%s: expected %v, got %v", tt.name, ErrInvalidScenario, err)
+		}
+	}
+
+	// the scenario of the urgency is the one validated and displayed
+	n := NewNotification("test_message", WithUrgency(Critical), snooze, WithAudio(LoopingAlarm), WithAudioLoop(true))
+	if toast := toastXML(n); toast.Scenario != string(ScenarioAlarm) || toast.Duration != string(Long) {
+		t.Errorf("expected a long alarm, got %q %q", toast.Scenario, toast.Duration)
+	}
+
+	// alarms and incoming calls loop their audio, the default one of the Windows notifications is silent
+	for _, tt := range []struct {
+		opts     []NotificationOption
+		expected toastxml.Audio
+	}{
+		{[]NotificationOption{WithScenario(ScenarioAlarm)}, toastxml.Audio{Src: string(LoopingAlarm), Loop: true}},
+		{[]NotificationOption{WithScenario(ScenarioIncomingCall)}, toastxml.Audio{Src: string(LoopingCall), Loop: true}},
+		{[]NotificationOption{WithScenario(ScenarioIncomingCall), WithAudio(LoopingCall3)}, toastxml.Audio{Src: string(LoopingCall3)}},
+		{[]NotificationOption{WithScenario(ScenarioAlarm), WithAudio(Silent)}, toastxml.Audio{Silent: true}},
+		{[]NotificationOption{WithScenario(ScenarioAlarm), WithSilent(true)}, toastxml.Audio{Silent: true}},
+		{[]NotificationOption{WithScenario(ScenarioReminder)}, toastxml.Audio{Silent: true}},
+	} {
+		n := &Notification{Audio: Silent}
+		for _, fn := range append(tt.opts, snooze) {
+			fn(n)
+		}
+		checkErr(t, validateScenario(n))
+		if toast := toastXML(n); toast.Audio == nil || *toast.Audio != tt.expected {
+			t.Errorf("%s: expected %+v, got %+v", n.Scenario, tt.expected, toast.Audio)
+		} else if tt.expected.Loop && toast.Duration != string(Long) {
+			t.Errorf("%s: expected a long toast, got %q", n.Scenario, toast.Duration)
+		}
+	}
+}
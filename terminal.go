@@ -0,0 +1,106 @@
+package toast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func init() {
+	Register("terminal", func() (Notifier, error) {
+		if fi, err := os.Stderr.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+			return nil, errors.New("stderr is not a terminal")
+		}
+		return terminal{w: os.Stderr, getenv: os.Getenv}, nil
+	})
+	Register("stderr", func() (Notifier, error) { return line{w: os.Stderr}, nil })
+}
+
+// terminal asks the terminal emulator to display the notification with an OSC escape sequence.
+//
+// OSC 9 is understood by iTerm2, WezTerm, Windows Terminal and ConEmu,
+// OSC 777 by VTE based terminals, foot, urxvt and WezTerm.
+type terminal struct {
+	w      io.Writer
+	getenv func(key string) string
+}
+
+func (t terminal) Notify(ctx context.Context, n *Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	seq, err := t.sequence(n)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(t.w, seq)
+	return err
+}
+
+func (t terminal) sequence(n *Notification) (string, error) {
+	title, message := stripControl(n.Title), stripControl(n.Message)
+	term := t.getenv("TERM")
+	switch {
+	case len(t.getenv("VTE_VERSION")) != 0,
+		strings.HasPrefix(term, "foot"),
+		strings.HasPrefix(term, "rxvt"):
+		return fmt.Sprintf("\x1b]777;notify;%s;%s\x1b\\", strings.ReplaceAll(title, ";", ","), message), nil
+	case t.getenv("TERM_PROGRAM") == "iTerm.app",
+		t.getenv("TERM_PROGRAM") == "WezTerm",
+		len(t.getenv("WT_SESSION")) != 0,
+		t.getenv("ConEmuANSI") == "ON":
+		if len(title) != 0 {
+			message = title + ": " + message
+		}
+		return fmt.Sprintf("\x1b]9;%s\x07", message), nil
+	default:
+		return "", fmt.Errorf("terminal %q does not support notifications", term)
+	}
+}
+
+func (terminal) droppedOptions(_ context.Context, n *Notification) []string {
+	return n.dropped()
+}
+
+func (t terminal) Capabilities(context.Context) (Caps, error) {
+	_, err := t.sequence(&Notification{})
+	return Caps{}, err
+}
+
+// line writes the notification as a single "title: message" line.
+type line struct {
+	w io.Writer
+}
+
+func (l line) Notify(ctx context.Context, n *Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	msg := strings.ReplaceAll(stripControl(n.Message), "\n", " ")
+	if title := stripControl(n.Title); len(title) != 0 {
+		msg = title + ": " + msg
+	}
+	_, err := fmt.Fprintln(l.w, msg)
+	return err
+}
+
+func (line) droppedOptions(_ context.Context, n *Notification) []string {
+	return n.dropped()
+}
+
+func (l line) Capabilities(context.Context) (Caps, error) {
+	return Caps{}, nil
+}
+
+// stripControl removes the control characters, except new lines, so text can't inject escape sequences.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r < 0x20 && r != '\n') || (r >= 0x7f && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, s)
+}
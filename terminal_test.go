@@ -0,0 +1,44 @@
+package toast
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestTerminal(t *testing.T) {
+	n := &Notification{Title: "test;title", Message: "test_message\x1b]0;pwned\x07"}
+	tests := []struct {
+		env      map[string]string
+		expected string
+	}{
+		{map[string]string{"VTE_VERSION": "7600"}, "\x1b]777;notify;test,title;test_message]0;pwned\x1b\\"},
+		{map[string]string{"TERM": "foot"}, "\x1b]777;notify;test,title;test_message]0;pwned\x1b\\"},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, "\x1b]9;test;title: test_message]0;pwned\x07"},
+		{map[string]string{"WT_SESSION": "1"}, "\x1b]9;test;title: test_message]0;pwned\x07"},
+		{map[string]string{"TERM": "dumb"}, ""},
+	}
+	for _, tt := range tests {
+		buf := bytes.NewBuffer(nil)
+		tm := terminal{w: buf, getenv: func(key string) string { return tt.env[key] }}
+		err := tm.Notify(context.Background(), n)
+		if len(tt.expected) == 0 {
+			if err == nil {
+				t.Errorf("%v: expected an error", tt.env)
+			}
+			continue
+		}
+		checkErr(t, err)
+		if buf.String() != tt.expected {
+			t.Errorf("%v: expected %q, got %q", tt.env, tt.expected, buf.String())
+		}
+	}
+}
+
+func TestLine(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	checkErr(t, line{w: buf}.Notify(context.Background(), &Notification{Title: "test_title", Message: "line1\nline2\a"}))
+	if expected := "test_title: line1 line2\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
@@ -1,14 +1,45 @@
 package toast
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrUnsupportedPlatform is matched by the error of Push on platforms without a notification backend,
+	// an UnsupportedPlatformError.
+	ErrUnsupportedPlatform = errors.New("toast: unsupported platform")
+
+	// ErrNotSupported is returned when the backend is unable to carry out a request.
+	ErrNotSupported = errors.New("toast: not supported by the backend")
+)
+
+// UnsupportedPlatformError is returned by Push on the platforms without a notification backend,
+// errors.Is matches it with ErrUnsupportedPlatform.
+type UnsupportedPlatformError struct {
+	GOOS, GOARCH string
+}
+
+func (e *UnsupportedPlatformError) Error() string {
+	return ErrUnsupportedPlatform.Error() + ": " + e.GOOS + "/" + e.GOARCH
+}
+
+func (e *UnsupportedPlatformError) Is(target error) bool {
+	return target == ErrUnsupportedPlatform
+}
+
 type Audio string
 
-type NotificationOption func(*notification)
+type NotificationOption func(*Notification)
 
 // WithTitle
 //
 // The main title/heading for the notification.
 func WithTitle(title string) NotificationOption {
-	return func(n *notification) {
+	return func(n *Notification) {
 		n.Title = title
 	}
 }
@@ -17,7 +48,7 @@ func WithTitle(title string) NotificationOption {
 //
 // The single/multi line message to display for the notification.
 func WithMessage(msg string) NotificationOption {
-	return func(n *notification) {
+	return func(n *Notification) {
 		n.Message = msg
 	}
 }
@@ -26,16 +57,209 @@ func WithMessage(msg string) NotificationOption {
 //
 // The audio to play when displaying the notification
 func WithAudio(audio Audio) NotificationOption {
-	return func(n *notification) {
+	return func(n *Notification) {
+		n.use("WithAudio")
 		n.Audio = audio
 	}
 }
 
-type notifier interface {
-	push() error
+// Notification is what a Notifier gets to display.
+//
+// It is encoded the same on every platform, in JSON and in YAML:
+// a Notification may be stored or sent over a wire and then handed to any Notifier.
+// The callbacks, the icon given WithIconRaw and the like, and WithStrict aren't encoded:
+// a decoded Notification has none of them and drops the options its Notifier can't honor.
+type Notification struct {
+	// The id the backend assigned to the notification once displayed,
+	// a notification with an id replaces the one displayed before.
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	// The main title/heading for the notification.
+	Title string `json:"title" yaml:"title"`
+
+	// The single/multi line message to display for the notification.
+	Message string `json:"message" yaml:"message"`
+
+	// The audio to play when displaying the notification
+	Audio Audio `json:"audio,omitempty" yaml:"audio,omitempty"`
+
+	// Optional action buttons to display below the notification title & message.
+	Actions []Action `json:"actions,omitempty" yaml:"actions,omitempty"`
+
+	// The subtitle of the notification.
+	Subtitle string `json:"subtitle,omitempty" yaml:"subtitle,omitempty"`
+
+	// The name of your app (the freedesktop app_name, the Windows AppUserModelID).
+	AppID string `json:"app_id,omitempty" yaml:"app_id,omitempty"`
+
+	// An optional path to an image, a freedesktop icon name or the URL of the image in the browser.
+	Icon string `json:"icon,omitempty" yaml:"icon,omitempty"`
+
+	// An image displayed as part of the notification.
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+
+	// An image displayed above the text of the Windows toast.
+	HeroImage string `json:"hero_image,omitempty" yaml:"hero_image,omitempty"`
+
+	// How the icon is cropped on Windows
+	IconCrop IconCrop `json:"icon_crop,omitempty" yaml:"icon_crop,omitempty"`
+
+	// The source of the content, displayed below the other text of the Windows toast
+	Attribution string `json:"attribution,omitempty" yaml:"attribution,omitempty"`
+
+	// Fakes the sender application of the notification on macOS.
+	// This uses the specified application’s icon, and will launch it when the notification is clicked.
+	BundleID string `json:"bundle_id,omitempty" yaml:"bundle_id,omitempty"`
+
+	// The urgency level of the notification (low/normal/critical)
+	Urgency Urgency `json:"urgency,omitempty" yaml:"urgency,omitempty"`
+
+	// How long the notification should show up for, the server decides when it's zero.
+	// It is encoded as a duration string ("1m30s").
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// Whether the notification shows up until the user closes it
+	Persistent bool `json:"persistent,omitempty" yaml:"persistent,omitempty"`
+
+	// The text boxes and selection boxes
+	Inputs []Input `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+
+	// An optional progress bar
+	Progress *Progress `json:"progress,omitempty" yaml:"progress,omitempty"`
+
+	// The Windows scenario of the toast, overriding the one of the urgency
+	Scenario Scenario `json:"scenario,omitempty" yaml:"scenario,omitempty"`
+
+	// The Windows activation type of the notification (like Action)
+	ActivationType string `json:"activation_type,omitempty" yaml:"activation_type,omitempty"`
+
+	// The activation/action arguments (invoked when the user clicks the notification)
+	ActivationArguments string `json:"activation_arguments,omitempty" yaml:"activation_arguments,omitempty"`
+
+	// Whether to loop the audio (default false)
+	Loop bool `json:"loop,omitempty" yaml:"loop,omitempty"`
+
+	// How long the toast should show up for on Windows (short/long)
+	Duration NotificationDuration `json:"duration,omitempty" yaml:"duration,omitempty"`
+
+	// The text direction of the notification in the browser
+	TextDirection TextDirection `json:"dir,omitempty" yaml:"dir,omitempty"`
+
+	// The language code of the notification in the browser
+	Lang string `json:"lang,omitempty" yaml:"lang,omitempty"`
+
+	// Whether the user should be notified after a new notification replaces an old one
+	Renotify bool `json:"renotify,omitempty" yaml:"renotify,omitempty"`
+
+	// Whether the notification should remain active until the user clicks or dismisses it
+	RequireInteraction bool `json:"require_interaction,omitempty" yaml:"require_interaction,omitempty"`
+
+	// Whether the notification should be silent, whatever the Audio
+	Silent bool `json:"silent,omitempty" yaml:"silent,omitempty"`
+
+	// The time at which the notification is created or applicable
+	Timestamp time.Time `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+
+	// The vibration pattern for devices with vibration hardware
+	Vibrate []int `json:"vibrate,omitempty" yaml:"vibrate,omitempty"`
+
+	_useObjC  bool
+	_iconRaw  []byte
+	_iconLoad func() ([]byte, error)
+	_onClick  func(event interface{})
+	_onShow   func()
+	_onClose  func()
+	_onError  func()
+
+	// fail rather than dropping the options the backend can't honor
+	strict bool
+	// the options given, see WithStrict
+	options []string
+
+	// the Notifier which displayed the notification
+	sender Notifier
+}
+
+// NewNotification returns the notification Push would display, with the defaults of the platform.
+func NewNotification(message string, opts ...NotificationOption) *Notification {
+	return newNotification(message, opts...)
+}
+
+// notificationJSON is the JSON encoding of a Notification.
+type notificationJSON struct {
+	plainNotification
+	Timeout   string     `json:"timeout,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// plainNotification has the fields, but not the methods, of Notification.
+type plainNotification Notification
+
+func (n Notification) MarshalJSON() ([]byte, error) {
+	data := notificationJSON{plainNotification: plainNotification(n)}
+	if n.Timeout != 0 {
+		data.Timeout = n.Timeout.String()
+	}
+	if !n.Timestamp.IsZero() {
+		data.Timestamp = &n.Timestamp
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON replaces n with the decoded notification, WithStrict and the callbacks n was given are lost.
+func (n *Notification) UnmarshalJSON(bs []byte) error {
+	var data notificationJSON
+	if err := json.Unmarshal(bs, &data); err != nil {
+		return err
+	}
+	*n = Notification(data.plainNotification)
+	if len(data.Timeout) != 0 {
+		timeout, err := time.ParseDuration(data.Timeout)
+		if err != nil {
+			return fmt.Errorf("toast: timeout: %w", err)
+		}
+		n.Timeout = timeout
+	}
+	if data.Timestamp != nil {
+		n.Timestamp = *data.Timestamp
+	}
+	return nil
 }
 
+// Push displays the message with the Notifier selected by Use,
+// the platform's default backend is used if Use has not been called.
 func Push(message string, opts ...NotificationOption) error {
-	var n notifier = newNotification(message, opts...)
-	return n.push()
+	return PushContext(context.Background(), message, opts...)
+}
+
+// PushContext is like Push but gives up, killing any process it started, once ctx is done.
+// In that case ctx.Err() is returned.
+func PushContext(ctx context.Context, message string, opts ...NotificationOption) error {
+	_, err := SendContext(ctx, message, opts...)
+	return err
+}
+
+// Send is like Push but returns a Handle to update or close the notification.
+func Send(message string, opts ...NotificationOption) (*Handle, error) {
+	return SendContext(context.Background(), message, opts...)
+}
+
+// SendContext is like Send but gives up once ctx is done, see PushContext.
+func SendContext(ctx context.Context, message string, opts ...NotificationOption) (*Handle, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	nf, err := current()
+	if err != nil {
+		return nil, err
+	}
+	n := newNotification(message, opts...)
+	if err = notify(ctx, nf, n); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	delivered(n, nf)
+	return &Handle{notifier: n.sender, n: n}, nil
 }
@@ -3,38 +3,39 @@
 package toast
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
 )
 
-func WithSubtitle(subtitle string) NotificationOption {
-	return func(n *notification) {
-		n.Subtitle = subtitle
+func defaultNotifier() (Notifier, error) {
+	return darwinDefault{}, nil
+}
+
+// darwinDefault tries the Objective-C backend first if WithObjectiveC was given, osascript otherwise.
+type darwinDefault struct{}
+
+func (darwinDefault) Notify(ctx context.Context, n *Notification) error {
+	if n._useObjC {
+		return Chain(objc{}, osascript{}).Notify(ctx, n)
 	}
+	return Chain(osascript{}, objc{}).Notify(ctx, n)
 }
 
-const (
-	Basso     Audio = "Basso"
-	Blow      Audio = "Blow"
-	Bottle    Audio = "Bottle"
-	Frog      Audio = "Frog"
-	Funk      Audio = "Funk"
-	Glass     Audio = "Glass"
-	Hero      Audio = "Hero"
-	Morse     Audio = "Morse"
-	Ping      Audio = "Ping"
-	Pop       Audio = "Pop"
-	Purr      Audio = "Purr"
-	Sosumi    Audio = "Sosumi"
-	Submarine Audio = "Submarine"
-	Tink      Audio = "Tink"
-)
+// WritesIcon is true as objc and osascript are handed the notification by the Notify of a Chain.
+func (darwinDefault) WritesIcon() bool { return true }
 
-var _ notifier = (*notification)(nil)
+func (darwinDefault) Capabilities(ctx context.Context) (Caps, error) {
+	return osascript{}.Capabilities(ctx)
+}
 
-func newNotification(message string, opts ...NotificationOption) *notification {
-	n := &notification{
+func init() {
+	Register("osascript", func() (Notifier, error) { return osascript{}, nil })
+}
+
+func newNotification(message string, opts ...NotificationOption) *Notification {
+	n := &Notification{
 		Title:   "GO APP",
 		Message: message,
 	}
@@ -44,51 +45,42 @@ func newNotification(message string, opts ...NotificationOption) *notification {
 	return n
 }
 
-func (n *notification) push() error {
-	if n._useObjC {
-		return n.pushWithObjC()
-	}
+// osascript displays notifications with AppleScript's `display notification`.
+type osascript struct{}
 
-	script := n.template()
+func (osascript) Notify(ctx context.Context, n *Notification) error {
+	script := appleScript(n)
 	osa, err := exec.LookPath("osascript")
 	if err != nil {
 		return err
 	}
-	cmd := exec.Command(osa, "-e", script)
+	cmd := exec.CommandContext(ctx, osa, "-e", script)
 	return cmd.Run()
 }
 
-func (n *notification) template() (script string) {
+func (osascript) Capabilities(context.Context) (Caps, error) {
+	return Caps{Sounds: true}, nil
+}
+
+// the options of osascript, objc adds WithUrgency
+var darwinOptions = []string{"WithAudio", "WithSilent", "WithSubtitle", "WithObjectiveC"}
+
+func (osascript) droppedOptions(_ context.Context, n *Notification) []string {
+	return n.dropped(darwinOptions...)
+}
+
+func appleScript(n *Notification) (script string) {
 	tpl := `display notification "%s" with title "%s"`
 	script = fmt.Sprintf(tpl, escapeNotificationString(n.Message), escapeNotificationString(n.Title))
 	if len(n.Subtitle) != 0 {
 		script += fmt.Sprintf(` subtitle "%s"`, escapeNotificationString(n.Subtitle))
 	}
-	if len(n.Audio) != 0 {
+	if len(n.Audio) != 0 && n.Audio != Silent && !n.Silent {
 		script += fmt.Sprintf(` sound name "%s"`, escapeNotificationString(string(n.Audio)))
 	}
 	return
 }
 
-type notification struct {
-	// The main title/heading for the notification.
-	Title string `json:"title"`
-
-	Subtitle string `json:"subtitle"`
-
-	// The single/multi line message to display for the notification.
-	Message string `json:"message"`
-
-	// The audio to play when displaying the notification
-	Audio Audio `json:"audio"`
-
-	_useObjC bool
-
-	// Fakes the sender application of the notification.
-	// This uses the specified application’s icon, and will launch it when the notification is clicked.
-	BundleID string `json:"bundle_id"`
-}
-
 func escapeNotificationString(s string) string {
 	s = strings.ReplaceAll(s, `\`, `\\`)
 	return strings.ReplaceAll(s, `"`, `\"`)
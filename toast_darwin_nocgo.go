@@ -0,0 +1,23 @@
+//go:build darwin && !cgo
+
+package toast
+
+import (
+	"context"
+	"fmt"
+)
+
+func init() {
+	Register("objc", func() (Notifier, error) { return objc{}, nil })
+}
+
+// objc needs cgo, see toast_darwin_objc.go.
+type objc struct{}
+
+func (objc) Capabilities(context.Context) (Caps, error) {
+	return Caps{}, ErrUnsupportedPlatform
+}
+
+func (objc) Notify(context.Context, *Notification) error {
+	return fmt.Errorf("%w: WithObjectiveC requires cgo", ErrUnsupportedPlatform)
+}
@@ -12,34 +12,84 @@ void Push(const char *strJson);
 */
 import "C"
 import (
+	"context"
 	"encoding/json"
+	"time"
 	"unsafe"
 )
 
-func WithObjectiveC(b ...bool) NotificationOption {
-	if len(b) == 0 {
-		b = []bool{true}
-	}
-	return func(n *notification) {
-		n._useObjC = b[0]
-	}
+func init() {
+	Register("objc", func() (Notifier, error) { return objc{}, nil })
 }
 
 // func WithFakeBundleID(bundleID string) NotificationOption {
-// 	return func(n *notification) {
+// 	return func(n *Notification) {
 // 		n._useObjC = true
 // 		n.BundleID = bundleID
 // 	}
 // }
 
-func (n *notification) pushWithObjC() error {
-	bsData, err := json.Marshal(n)
+// objc displays notifications through NSUserNotificationCenter.
+type objc struct{}
+
+func (objc) Capabilities(context.Context) (Caps, error) {
+	return Caps{Sounds: true}, nil
+}
+
+func (objc) droppedOptions(_ context.Context, n *Notification) []string {
+	return n.dropped(append(append(darwinOptions, "WithUrgency", "WithIcon"), iconOptions...)...)
+}
+
+func (objc) Notify(ctx context.Context, n *Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// the keys read by Push
+	data := struct {
+		Title    string `json:"title"`
+		Subtitle string `json:"subtitle"`
+		Message  string `json:"message"`
+		Audio    Audio  `json:"audio"`
+		BundleID string `json:"bundle_id"`
+		// The path of the content image
+		Icon string `json:"icon"`
+		// The UNNotificationInterruptionLevel
+		InterruptionLevel string `json:"interruption_level"`
+		// How long Push waits for the delivery, in seconds (zero is its default of 200s)
+		Timeout float64 `json:"timeout"`
+	}{
+		Title:    n.Title,
+		Subtitle: n.Subtitle,
+		Message:  n.Message,
+		Audio:    n.Audio,
+		BundleID: n.BundleID,
+		Icon:     n.Icon,
+
+		InterruptionLevel: n.Urgency.interruptionLevel(),
+	}
+	if n.Audio == Silent || n.Silent {
+		data.Audio = ""
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		data.Timeout = time.Until(deadline).Seconds()
+	}
+	bsData, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 	cs := C.CString(string(bsData))
-	defer C.free(unsafe.Pointer(cs))
 
-	C.Push(cs)
-	return nil
+	// the run loop can't be interrupted, it stops by itself once the timeout is reached
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer C.free(unsafe.Pointer(cs))
+		C.Push(cs)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -79,14 +79,31 @@ void Push(const char *strJson)
     if (![@"" isEqualToString:mData[@"audio"]]){
         notice.soundName = mData[@"audio"];
     }
+    if (![@"" isEqualToString:mData[@"icon"]]){
+        notice.contentImage = [[NSImage alloc] initWithContentsOfFile:mData[@"icon"]];
+    }
+    // NSUserNotification predates interruption levels, the private key lets the notification through Do Not Disturb
+    NSString *level = mData[@"interruption_level"];
+    if ([@"timeSensitive" isEqualToString:level] || [@"critical" isEqualToString:level]) {
+        @try {
+            [notice setValue:@YES forKey:@"_ignoresDoNotDisturb"];
+        } @catch (NSException *e) {
+        }
+    }
 
     [nc deliverNotification:notice];
 
+    int limit = 2000;
+    double timeout = [mData[@"timeout"] doubleValue];
+    if (timeout > 0) {
+        limit = (int)(timeout / 0.1);
+    }
+
     int i = 0;
     while (!ncDelegate.didDeliver) {
         [[NSRunLoop currentRunLoop] runUntilDate:[NSDate dateWithTimeIntervalSinceNow:0.1]];
         i++;
-        if (i > 2000) {
+        if (i > limit) {
             break;
         }
     }
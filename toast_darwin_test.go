@@ -14,9 +14,3 @@ func TestPush(t *testing.T) {
 
 	// checkErr(t, Push("test_message", WithSubtitle("test_subtitle"), WithFakeBundleID("com.apple.Safari")))
 }
-
-func checkErr(t *testing.T, err error) {
-	if err != nil {
-		t.Fatal(err)
-	}
-}
@@ -3,172 +3,56 @@
 package toast
 
 import (
+	"context"
+	"strconv"
+	"sync"
 	"syscall/js"
-	"time"
 )
 
-// WithIcon
-//
-// The URL of the image used as an icon of the notification
-func WithIcon(urlIcon string) NotificationOption {
-	return func(n *notification) {
-		n._options["icon"] = urlIcon
-	}
-}
-
-// WithTextDirection
-//
-// The text direction of the notification
-func WithTextDirection(dir TextDirection) NotificationOption {
-	return func(n *notification) {
-		n._options["dir"] = string(dir)
-	}
-}
-
-// WithLang
-//
-// The language code of the notification
-func WithLang(lang string) NotificationOption {
-	return func(n *notification) {
-		n._options["lang"] = lang
-	}
-}
-
-// WithNotificationID
-//
-// The ID of the notification (if any)
-func WithNotificationID(tag string) NotificationOption {
-	return func(n *notification) {
-		n._options["tag"] = tag
-	}
-}
-
-// WithImage
-//
-// The URL of an image to be displayed as part of the notification
-func WithImage(urlImage string) NotificationOption {
-	return func(n *notification) {
-		n._options["image"] = urlImage
-	}
-}
-
-// WithRenotify
-//
-// Specifies whether the user should be notified after a new notification replaces an old one
-func WithRenotify(b bool) NotificationOption {
-	return func(n *notification) {
-		n._options["renotify"] = b
-	}
-}
-
-// WithRequireInteraction
-//
-// indicating that a notification should remain active until the user clicks or dismisses it,
-// rather than closing automatically.
-func WithRequireInteraction(b bool) NotificationOption {
-	return func(n *notification) {
-		n._options["requireInteraction"] = b
-	}
+func defaultNotifier() (Notifier, error) {
+	return browser{}, nil
 }
 
-// WithSilent
-//
-// Specifies whether the notification should be silent — i.e., no sounds or vibrations should be issued,
-// regardless of the device settings.
-func WithSilent(b bool) NotificationOption {
-	return func(n *notification) {
-		n._options["silent"] = b
-	}
-}
-
-// WithTimestamp
-//
-// Specifies the time at which a notification is created or applicable (past, present, or future).
-func WithTimestamp(t time.Time) NotificationOption {
-	return func(n *notification) {
-		n._options["timestamp"] = t.Unix()
-	}
-}
-
-// WithVibrate
-//
-// Specifies a vibration pattern for devices with vibration hardware to emit.
-func WithVibrate(v []int) NotificationOption {
-	return func(n *notification) {
-		_v := make([]interface{}, len(v))
-		for i := range v {
-			_v[i] = v[i]
-		}
-		n._options["vibrate"] = _v
-	}
+func init() {
+	Register("browser", func() (Notifier, error) { return browser{}, nil })
 }
 
-// WithOnClick
-//
-// A handler for the click event.
-// It is triggered each time the user clicks on the notification.
-func WithOnClick(fn func(event interface{})) NotificationOption {
-	return func(n *notification) {
-		n._onClick = fn
+func newNotification(message string, opts ...NotificationOption) *Notification {
+	n := &Notification{
+		Title:   js.Global().Get("location").Get("href").String(),
+		Message: message,
 	}
-}
-
-// WithOnShow
-//
-// A handler for the show event.
-// It is triggered when the notification is displayed.
-func WithOnShow(fn func()) NotificationOption {
-	return func(n *notification) {
-		n._onShow = fn
-	}
-}
-
-// WithOnClose
-//
-// A handler for the close event.
-// It is triggered when the user closes the notification.
-func WithOnClose(fn func()) NotificationOption {
-	return func(n *notification) {
-		n._onClose = fn
-	}
-}
-
-// WithOnError
-//
-// A handler for the error event.
-// It is triggered each time the notification encounters an error.
-func WithOnError(fn func()) NotificationOption {
-	return func(n *notification) {
-		n._onError = fn
+	for _, fn := range opts {
+		fn(n)
 	}
+	return n
 }
 
-type TextDirection string
-
-const (
-	// Auto adopts the browser's language setting behavior (the default.)
-	Auto TextDirection = "auto"
-	// LTR left to right
-	LTR TextDirection = "ltr"
-	// RTL right to left
-	RTL TextDirection = "rtl"
+var (
+	_lastTag int
+	// the displayed notifications by tag, so that they can be closed
+	_displayed   = make(map[string]js.Value)
+	_displayedMu sync.Mutex
 )
 
-var _ notifier = (*notification)(nil)
+// browser displays notifications with the Notifications API.
+type browser struct{}
 
-func newNotification(message string, opts ...NotificationOption) *notification {
-	n := &notification{
-		Title:    js.Global().Get("location").Get("href").String(),
-		Message:  message,
-		_options: make(map[string]interface{}, 16),
+func (browser) Notify(ctx context.Context, n *Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	for _, fn := range opts {
-		fn(n)
+	icon, err := iconDataURL(n, browserIconSize)
+	if err != nil {
+		return err
+	}
+	n.Icon = icon
+	if len(n.ID) == 0 {
+		_displayedMu.Lock()
+		_lastTag++
+		n.ID = "go-toast-" + strconv.Itoa(_lastTag)
+		_displayedMu.Unlock()
 	}
-	return n
-}
-
-func (n *notification) push() error {
 	// check if the browser supports notifications
 	if !isSupported() {
 		alert("This browser does not support desktop notification")
@@ -176,7 +60,7 @@ func (n *notification) push() error {
 	}
 	// check whether notification permissions have already been granted
 	if isGranted() {
-		n.createNotification()
+		createNotification(n)
 		return nil
 	}
 	// need to ask the user for permission
@@ -186,7 +70,7 @@ func (n *notification) push() error {
 			Call("requestPermission").
 			Call("then",
 				js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-					n.createNotification()
+					createNotification(n)
 					return nil
 				}),
 			)
@@ -194,14 +78,71 @@ func (n *notification) push() error {
 	return nil
 }
 
-func (n *notification) createNotification() {
-	notify := js.Global().Get("Notification").New(n.Title, js.ValueOf(n.generateOptions()))
-	if n._onClick != nil {
+// Capabilities reports the click on the notification only (DefaultAction), MaxActions stays 0:
+// buttons need a service worker.
+func (browser) Capabilities(context.Context) (Caps, error) {
+	return Caps{Actions: true, Images: true, Persistence: true}, nil
+}
+
+// the options of the Notifications API, only DefaultAction is reported back
+var browserOptions = []string{
+	"WithAction", "WithIcon", "WithIconRaw", "WithIconImage", "WithIconFS", "WithIconReader", "WithImage", "WithInlineImage",
+	"WithUrgency", "WithTextDirection", "WithLang", "WithRenotify", "WithRequireInteraction",
+	"WithTimeout", "WithPersistent", "WithTimestamp", "WithVibrate", "WithSilent", "WithNotificationID",
+	"WithOnClick", "WithOnShow", "WithOnClose", "WithOnError",
+}
+
+func (browser) droppedOptions(_ context.Context, n *Notification) []string {
+	return n.dropped(browserOptions...)
+}
+
+// WritesIcon is true as the icon given WithIconRaw and the like is passed as a data: URL.
+func (browser) WritesIcon() bool { return true }
+
+// Close closes the notification if it is still displayed.
+func (browser) Close(ctx context.Context, n *Notification) error {
+	_displayedMu.Lock()
+	notify, ok := _displayed[n.ID]
+	delete(_displayed, n.ID)
+	_displayedMu.Unlock()
+	if ok {
+		notify.Call("close")
+	}
+	return nil
+}
+
+func createNotification(n *Notification) {
+	notify := js.Global().Get("Notification").New(n.Title, js.ValueOf(generateOptions(n)))
+	_displayedMu.Lock()
+	_displayed[n.ID] = notify
+	_displayedMu.Unlock()
+	notify.Call("addEventListener", "close", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		_displayedMu.Lock()
+		if _displayed[n.ID].Equal(this) {
+			delete(_displayed, n.ID)
+		}
+		_displayedMu.Unlock()
+		return nil
+	}))
+	if n._onClick != nil || n.hasCallbacks() {
 		notify.Set("onclick", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-			n._onClick(this)
+			if n._onClick != nil {
+				n._onClick(this)
+			}
+			// buttons need a service worker, only clicking the notification itself is reported
+			n.DispatchAction(ActionEvent{NotificationID: n.ID, ActionID: DefaultAction})
 			return nil
 		}))
 	}
+	if n.Timeout > 0 {
+		var closeFn js.Func
+		closeFn = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			closeFn.Release()
+			notify.Call("close")
+			return nil
+		})
+		js.Global().Call("setTimeout", closeFn, n.Timeout.Milliseconds())
+	}
 	if n._onShow != nil {
 		notify.Set("onshow", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 			n._onShow()
@@ -222,31 +163,45 @@ func (n *notification) createNotification() {
 	}
 }
 
-func (n *notification) generateOptions() (options map[string]interface{}) {
+// https://developer.mozilla.org/en-US/docs/Web/API/notification
+func generateOptions(n *Notification) (options map[string]interface{}) {
 	options = make(map[string]interface{}, 16)
 	options["body"] = n.Message
-	for k, v := range n._options {
-		options[k] = v
+	options["tag"] = n.ID
+	if len(n.Icon) != 0 {
+		options["icon"] = n.Icon
+	}
+	if len(n.Image) != 0 {
+		options["image"] = n.Image
+	}
+	if len(n.TextDirection) != 0 {
+		options["dir"] = string(n.TextDirection)
+	}
+	if len(n.Lang) != 0 {
+		options["lang"] = n.Lang
+	}
+	if n.Renotify {
+		options["renotify"] = true
+	}
+	if n.RequireInteraction || n.Persistent || n.Urgency.requireInteraction() {
+		options["requireInteraction"] = true
+	}
+	if n.Silent {
+		options["silent"] = true
+	}
+	if !n.Timestamp.IsZero() {
+		options["timestamp"] = n.Timestamp.Unix()
+	}
+	if len(n.Vibrate) != 0 {
+		v := make([]interface{}, len(n.Vibrate))
+		for i := range n.Vibrate {
+			v[i] = n.Vibrate[i]
+		}
+		options["vibrate"] = v
 	}
 	return
 }
 
-// https://developer.mozilla.org/en-US/docs/Web/API/notification
-type notification struct {
-	// The title of the notification
-	Title string
-
-	// The body string of the notification
-	Message string
-	Audio   Audio
-
-	_options map[string]interface{}
-	_onClick func(event interface{})
-	_onShow  func()
-	_onClose func()
-	_onError func()
-}
-
 func alert(msg string) {
 	js.Global().Call("alert", msg)
 }
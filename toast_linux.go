@@ -0,0 +1,268 @@
+//go:build linux && !android
+
+package toast
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusNotificationsName      = "org.freedesktop.Notifications"
+	dbusNotificationsPath      = "/org/freedesktop/Notifications"
+	dbusNotificationsInterface = "org.freedesktop.Notifications"
+)
+
+var connectSessionBus = dbus.ConnectSessionBus
+
+// defaultNotifier tries the session bus first, then notify-send and gdbus.
+func defaultNotifier() (Notifier, error) {
+	return Chain(registered("dbus"), registered("notify-send"), registered("gdbus")), nil
+}
+
+func init() {
+	Register("dbus", func() (Notifier, error) { return dbusNotifier{}, nil })
+}
+
+func newNotification(message string, opts ...NotificationOption) *Notification {
+	n := &Notification{
+		Title:   "GO APP",
+		Message: message,
+		AppID:   "GO APP",
+	}
+	for _, fn := range opts {
+		fn(n)
+	}
+	return n
+}
+
+// dbusNotifier talks to org.freedesktop.Notifications on the session bus.
+type dbusNotifier struct{}
+
+func (dbusNotifier) Notify(ctx context.Context, n *Notification) error {
+	if n.hasCallbacks() || n._onClose != nil {
+		return notifyAndListen(ctx, n)
+	}
+	conn, err := connectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	id, err := pushWithDBus(ctx, conn, n, nil)
+	if err != nil {
+		return err
+	}
+	n.ID = strconv.FormatUint(uint64(id), 10)
+	return nil
+}
+
+// Close calls org.freedesktop.Notifications.CloseNotification.
+func (dbusNotifier) Close(ctx context.Context, n *Notification) error {
+	id := replacesID(n)
+	if id == 0 {
+		return nil
+	}
+	conn, err := connectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	obj := conn.Object(dbusNotificationsName, dbusNotificationsPath)
+	return obj.CallWithContext(ctx, dbusNotificationsInterface+".CloseNotification", 0, id).Err
+}
+
+// Capabilities calls org.freedesktop.Notifications.GetCapabilities.
+func (dbusNotifier) Capabilities(ctx context.Context) (Caps, error) {
+	conn, err := connectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return Caps{}, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	list, err := serverCapabilities(ctx, conn)
+	if err != nil {
+		return Caps{}, err
+	}
+	return freedesktopCaps(list), nil
+}
+
+func serverCapabilities(ctx context.Context, conn *dbus.Conn) (list []string, err error) {
+	obj := conn.Object(dbusNotificationsName, dbusNotificationsPath)
+	err = obj.CallWithContext(ctx, dbusNotificationsInterface+".GetCapabilities", 0).Store(&list)
+	return
+}
+
+// https://specifications.freedesktop.org/notification-spec/latest/protocol.html#command-get-capabilities
+func freedesktopCaps(list []string) Caps {
+	var caps Caps
+	for _, c := range list {
+		switch c {
+		case "actions":
+			caps.Actions = true
+			caps.MaxActions = maxActions
+		case "body-markup":
+			caps.BodyMarkup = true
+		case "body-images", "icon-static", "icon-multi":
+			caps.Images = true
+		case "sound":
+			caps.Sounds = true
+		case "persistence":
+			caps.Persistence = true
+		case inlineReply:
+			caps.Inputs = true
+		}
+	}
+	return caps
+}
+
+// pushWithDBus calls org.freedesktop.Notifications.Notify and returns the id assigned by the server.
+// The send button of reply, if not nil, is displayed as an inline reply.
+//
+// https://specifications.freedesktop.org/notification-spec/latest/protocol.html#command-notify
+func pushWithDBus(ctx context.Context, conn *dbus.Conn, n *Notification, reply *Input) (id uint32, err error) {
+	h := hints(n)
+	if reply != nil && len(reply.Placeholder) != 0 {
+		h["x-kde-reply-placeholder-text"] = dbus.MakeVariant(reply.Placeholder)
+	}
+	icon, err := dbusIcon(n, h)
+	if err != nil {
+		return 0, err
+	}
+	obj := conn.Object(dbusNotificationsName, dbusNotificationsPath)
+	call := obj.CallWithContext(ctx, dbusNotificationsInterface+".Notify", 0,
+		n.AppID,
+		replacesID(n),
+		icon,
+		n.Title,
+		n.Message,
+		actions(n, reply),
+		h,
+		expireTimeout(n),
+	)
+	if call.Err != nil {
+		return 0, call.Err
+	}
+	err = call.Store(&id)
+	return
+}
+
+// WritesIcon is true as the icon given WithIconRaw and the like is passed in the image-data hint.
+func (dbusNotifier) WritesIcon() bool { return true }
+
+// dbusIcon returns the app_icon of n. The pixels of the icon given WithIconRaw and the like are passed
+// in the image-data hint instead, unless there's an image-path already: the icon is written to a file then,
+// like when it can't be decoded.
+func dbusIcon(n *Notification, h map[string]dbus.Variant) (string, error) {
+	if n._iconRaw == nil && n._iconLoad == nil {
+		return n.Icon, nil
+	}
+	raw, err := loadIcon(n)
+	if err != nil {
+		return "", err
+	}
+	if len(n.Image) == 0 {
+		pixels, err := decodeImage(raw, freedesktopIconSize)
+		if err != nil {
+			return "", err
+		}
+		if pixels != nil {
+			h["image-data"] = dbus.MakeVariant(imageData{
+				Width:         int32(pixels.Rect.Dx()),
+				Height:        int32(pixels.Rect.Dy()),
+				Rowstride:     int32(pixels.Stride),
+				HasAlpha:      true,
+				BitsPerSample: 8,
+				Channels:      4,
+				Data:          pixels.Pix,
+			})
+			return "", nil
+		}
+	}
+	if err = materializeIcon(n, freedesktopIconSize); err != nil {
+		return "", err
+	}
+	return n.Icon, nil
+}
+
+// imageData is the (iiibiiay) value of the image-data hint.
+type imageData struct {
+	Width, Height, Rowstride int32
+	HasAlpha                 bool
+	BitsPerSample, Channels  int32
+	Data                     []byte
+}
+
+// https://specifications.freedesktop.org/notification-spec/latest/hints.html
+func hints(n *Notification) map[string]dbus.Variant {
+	hints := make(map[string]dbus.Variant, 4)
+	if len(n.Urgency) != 0 {
+		hints["urgency"] = dbus.MakeVariant(n.Urgency.freedesktop())
+	}
+	if silent(n) {
+		hints["suppress-sound"] = dbus.MakeVariant(true)
+	} else if len(n.Audio) != 0 {
+		hints["sound-name"] = dbus.MakeVariant(string(n.Audio))
+	}
+	if len(n.Image) != 0 {
+		hints["image-path"] = dbus.MakeVariant(n.Image)
+	}
+	if value, ok := progressValue(n); ok {
+		hints["value"] = dbus.MakeVariant(value)
+	}
+	return hints
+}
+
+// progressValue returns the value hint in percent, false without a determinate progress bar.
+func progressValue(n *Notification) (int32, bool) {
+	if n.Progress == nil || n.Progress.Value < 0 {
+		return 0, false
+	}
+	return int32(clamp(n.Progress.Value)*100 + 0.5), true
+}
+
+func silent(n *Notification) bool {
+	return n.Silent || n.Audio == Silent
+}
+
+// the options mapped to the arguments of Notify
+var freedesktopOptions = []string{
+	"WithAudio", "WithAppID", "WithIcon", "WithIconRaw", "WithIconImage", "WithIconFS", "WithIconReader", "WithImage",
+	"WithInlineImage", "WithUrgency", "WithTimeout", "WithPersistent", "WithProgress", "WithSilent", "WithNotificationID",
+}
+
+func (dbusNotifier) droppedOptions(_ context.Context, n *Notification) []string {
+	return n.dropped(append(freedesktopOptions, "WithAction", "WithTextInput", "WithOnClose")...)
+}
+
+// replacesID returns the id of the notification to replace, zero if there's none.
+func replacesID(n *Notification) uint32 {
+	id, err := strconv.ParseUint(n.ID, 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(id)
+}
+
+// expireTimeout returns the expire_timeout in milliseconds, -1 lets the server decide and 0 never expires.
+func expireTimeout(n *Notification) int32 {
+	if n.Persistent {
+		return 0
+	}
+	if n.Timeout <= 0 {
+		return -1
+	}
+	if n.Timeout >= math.MaxInt32*time.Millisecond {
+		return math.MaxInt32
+	}
+	// rounded up, a timeout under 1ms isn't one that never expires
+	return int32((n.Timeout + time.Millisecond - 1) / time.Millisecond)
+}
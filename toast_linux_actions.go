@@ -0,0 +1,160 @@
+//go:build linux && !android
+
+package toast
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+var (
+	// the connections listening for the signals of a notification, by id
+	_listeners   = make(map[uint32]*dbus.Conn)
+	_listenersMu sync.Mutex
+)
+
+// inlineReply is the capability of the servers displaying a text box in the notification,
+// the key of the action sending it and the signal passing its value is NotificationReplied.
+const inlineReply = "inline-reply"
+
+// notifyAndListen displays n, then listens for the ActionInvoked and NotificationReplied signals of the notification
+// until it is closed, calling the WithOnClose callback then. The connection isn't tied to ctx as it outlives the call,
+// connecting gives up once ctx is done though.
+//
+// https://specifications.freedesktop.org/notification-spec/latest/protocol.html#signals
+func notifyAndListen(ctx context.Context, n *Notification) error {
+	conn, err := connectListener(ctx)
+	if err != nil {
+		return err
+	}
+	signals := make(chan *dbus.Signal, 8)
+	conn.Signal(signals)
+	for _, member := range []string{"ActionInvoked", "NotificationReplied", "NotificationClosed"} {
+		err = conn.AddMatchSignalContext(ctx,
+			dbus.WithMatchObjectPath(dbusNotificationsPath),
+			dbus.WithMatchInterface(dbusNotificationsInterface),
+			dbus.WithMatchMember(member),
+		)
+		if err != nil {
+			_ = conn.Close()
+			return err
+		}
+	}
+
+	reply := n.replyInput()
+	if reply != nil {
+		if list, err := serverCapabilities(ctx, conn); err != nil || !contains(list, inlineReply) {
+			reply = nil
+		}
+	}
+	id, err := pushWithDBus(ctx, conn, n, reply)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	n.ID = strconv.FormatUint(uint64(id), 10)
+
+	_listenersMu.Lock()
+	if previous, ok := _listeners[id]; ok {
+		// the notification was replaced, its callbacks with it
+		_ = previous.Close()
+	}
+	_listeners[id] = conn
+	_listenersMu.Unlock()
+
+	// the callbacks see the notification as it was displayed, even if it is updated meanwhile
+	displayed := &Notification{ID: n.ID, Actions: append([]Action(nil), n.Actions...), _onClose: n._onClose}
+	go listen(conn, signals, id, displayed, reply)
+	return nil
+}
+
+// connectListener connects to the session bus for a listener, giving up once ctx is done. The connection can't be
+// given ctx with dbus.WithContext, it would be closed along with it.
+func connectListener(ctx context.Context) (*dbus.Conn, error) {
+	type result struct {
+		conn *dbus.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	connect := connectSessionBus
+	go func() {
+		conn, err := connect()
+		done <- result{conn, err}
+	}()
+	select {
+	case r := <-done:
+		return r.conn, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.conn != nil {
+				_ = r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+func listen(conn *dbus.Conn, signals <-chan *dbus.Signal, id uint32, n *Notification, reply *Input) {
+	defer func() {
+		_listenersMu.Lock()
+		if _listeners[id] == conn {
+			delete(_listeners, id)
+		}
+		_listenersMu.Unlock()
+		_ = conn.Close()
+	}()
+
+	for sig := range signals {
+		if len(sig.Body) < 2 {
+			continue
+		}
+		if sigID, ok := sig.Body[0].(uint32); !ok || sigID != id {
+			continue
+		}
+		switch sig.Name {
+		case dbusNotificationsInterface + ".ActionInvoked":
+			if key, ok := sig.Body[1].(string); ok {
+				n.DispatchAction(ActionEvent{NotificationID: n.ID, ActionID: key})
+			}
+		case dbusNotificationsInterface + ".NotificationReplied":
+			if text, ok := sig.Body[1].(string); ok && reply != nil {
+				n.DispatchAction(ActionEvent{NotificationID: n.ID, ActionID: reply.ID, Inputs: map[string]string{reply.ID: text}})
+			}
+		case dbusNotificationsInterface + ".NotificationClosed":
+			if n._onClose != nil {
+				n._onClose()
+			}
+			return
+		}
+	}
+}
+
+// actions returns the actions parameter of Notify, a list of alternating keys and labels.
+// The send button of reply is the inline reply. The actions without callback, like the protocol ones, aren't listed:
+// clicking them would do nothing.
+func actions(n *Notification, reply *Input) []string {
+	list := make([]string, 0, 2*len(n.Actions))
+	for _, a := range n.Actions {
+		if len(a.ID) == 0 || a.fn == nil {
+			continue
+		}
+		key := a.ID
+		if reply != nil && key == reply.ID {
+			key = inlineReply
+		}
+		list = append(list, key, a.Label)
+	}
+	return list
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
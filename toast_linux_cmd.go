@@ -0,0 +1,253 @@
+//go:build linux && !android
+
+package toast
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+func init() {
+	// the server can't be asked, icon names are the one thing every server displays
+	Register("notify-send", newCommand(command{
+		path: "notify-send",
+		args: notifySendArgs,
+		ids:  notifySendIDs,
+		id:   strings.TrimSpace,
+		caps: Caps{Images: true},
+	}))
+	Register("gdbus", newCommand(command{
+		path:      "gdbus",
+		args:      gdbusArgs,
+		id:        gdbusID,
+		closeArgs: gdbusCloseArgs,
+		caps:      Caps{Images: true},
+	}))
+}
+
+// command displays notifications by running an executable found in PATH.
+type command struct {
+	path string
+	args func(n *Notification, ids bool) []string
+
+	// ids reports whether the command at path prints the notification id and replaces one by id,
+	// nil if it always does
+	ids func(ctx context.Context, path string) bool
+
+	// id extracts the notification id from the output of the command
+	id func(stdout string) string
+
+	// closeArgs returns the arguments withdrawing n, nil if the command can't do that
+	closeArgs func(n *Notification) []string
+
+	caps Caps
+}
+
+func newCommand(c command) NotifierFactory {
+	return func() (Notifier, error) {
+		path, err := exec.LookPath(c.path)
+		if err != nil {
+			return nil, err
+		}
+		found := c
+		found.path = path
+		return found, nil
+	}
+}
+
+func (c command) Notify(ctx context.Context, n *Notification) error {
+	ids := c.ids == nil || c.ids(ctx, c.path)
+	stdout, err := runCommand(exec.CommandContext(ctx, c.path, c.args(n, ids)...))
+	if err != nil {
+		return err
+	}
+	if id := c.id(stdout); replacesID(&Notification{ID: id}) != 0 {
+		n.ID = id
+	}
+	return nil
+}
+
+// Actions aren't supported, they need a connection to the session bus.
+func (c command) droppedOptions(ctx context.Context, n *Notification) []string {
+	if c.ids != nil && !c.ids(ctx, c.path) {
+		return n.dropped(without(freedesktopOptions, "WithNotificationID")...)
+	}
+	return n.dropped(freedesktopOptions...)
+}
+
+// without returns options but name.
+func without(options []string, name string) []string {
+	kept := make([]string, 0, len(options))
+	for _, option := range options {
+		if option != name {
+			kept = append(kept, option)
+		}
+	}
+	return kept
+}
+
+func (c command) Capabilities(context.Context) (Caps, error) {
+	return c.caps, nil
+}
+
+func (c command) Close(ctx context.Context, n *Notification) error {
+	if c.closeArgs == nil {
+		return fmt.Errorf("%w: %s can't close notifications", ErrNotSupported, c.path)
+	}
+	if replacesID(n) == 0 {
+		return nil
+	}
+	_, err := runCommand(exec.CommandContext(ctx, c.path, c.closeArgs(n)...))
+	return err
+}
+
+var _notifySendIDs sync.Map // path -> bool
+
+// notifySendIDs reports whether the notify-send at path has --print-id and --replace-id, added in libnotify 0.7.10:
+// the older ones (Ubuntu 20.04, Debian 11) exit on unknown options. It's asked once with --help.
+func notifySendIDs(ctx context.Context, path string) bool {
+	if ids, ok := _notifySendIDs.Load(path); ok {
+		return ids.(bool)
+	}
+	help, err := exec.CommandContext(ctx, path, "--help").Output()
+	if ctx.Err() != nil {
+		// asked again by the next notification
+		return false
+	}
+	ids := err == nil && bytes.Contains(help, []byte("--print-id"))
+	_notifySendIDs.Store(path, ids)
+	return ids
+}
+
+// https://man.archlinux.org/man/notify-send.1
+func notifySendArgs(n *Notification, ids bool) []string {
+	args := make([]string, 0, 12)
+	if ids {
+		args = append(args, "--print-id")
+		if id := replacesID(n); id != 0 {
+			args = append(args, "--replace-id="+strconv.FormatUint(uint64(id), 10))
+		}
+	}
+	if len(n.AppID) != 0 {
+		args = append(args, "--app-name="+n.AppID)
+	}
+	if len(n.Urgency) != 0 {
+		args = append(args, "--urgency="+string(n.Urgency))
+	}
+	if len(n.Icon) != 0 {
+		args = append(args, "--icon="+n.Icon)
+	}
+	if timeout := expireTimeout(n); timeout >= 0 {
+		args = append(args, "--expire-time="+strconv.Itoa(int(timeout)))
+	}
+	if silent(n) {
+		args = append(args, "--hint=boolean:suppress-sound:true")
+	} else if len(n.Audio) != 0 {
+		args = append(args, "--hint=string:sound-name:"+string(n.Audio))
+	}
+	if len(n.Image) != 0 {
+		args = append(args, "--hint=string:image-path:"+n.Image)
+	}
+	if value, ok := progressValue(n); ok {
+		args = append(args, "--hint=int:value:"+strconv.Itoa(int(value)))
+	}
+	// everything after "--" is taken literally, even if the title starts with a dash
+	return append(args, "--", n.Title, n.Message)
+}
+
+// https://docs.gtk.org/glib/gvariant-text-format.html
+func gdbusArgs(n *Notification, _ bool) []string {
+	hints := make([]string, 0, 3)
+	if len(n.Urgency) != 0 {
+		hints = append(hints, fmt.Sprintf("'urgency': <byte %d>", n.Urgency.freedesktop()))
+	}
+	if silent(n) {
+		hints = append(hints, "'suppress-sound': <true>")
+	} else if len(n.Audio) != 0 {
+		hints = append(hints, "'sound-name': <"+quoteGVariant(string(n.Audio))+">")
+	}
+	if len(n.Image) != 0 {
+		hints = append(hints, "'image-path': <"+quoteGVariant(n.Image)+">")
+	}
+	if value, ok := progressValue(n); ok {
+		hints = append(hints, "'value': <int32 "+strconv.Itoa(int(value))+">")
+	}
+	return []string{
+		"call", "--session",
+		"--dest", dbusNotificationsName,
+		"--object-path", dbusNotificationsPath,
+		"--method", dbusNotificationsInterface + ".Notify",
+		quoteGVariant(n.AppID),
+		"uint32 " + strconv.FormatUint(uint64(replacesID(n)), 10),
+		quoteGVariant(n.Icon),
+		quoteGVariant(n.Title),
+		quoteGVariant(n.Message),
+		"@as []",
+		"@a{sv} {" + strings.Join(hints, ", ") + "}",
+		"int32 " + strconv.Itoa(int(expireTimeout(n))),
+	}
+}
+
+func gdbusCloseArgs(n *Notification) []string {
+	return []string{
+		"call", "--session",
+		"--dest", dbusNotificationsName,
+		"--object-path", dbusNotificationsPath,
+		"--method", dbusNotificationsInterface + ".CloseNotification",
+		"uint32 " + strconv.FormatUint(uint64(replacesID(n)), 10),
+	}
+}
+
+// gdbusID extracts the id from the reply of Notify, printed as "(uint32 7,)".
+func gdbusID(stdout string) string {
+	stdout = strings.TrimSpace(stdout)
+	stdout = strings.TrimPrefix(stdout, "(uint32 ")
+	return strings.TrimSuffix(stdout, ",)")
+}
+
+// quoteGVariant returns s as a GVariant text format string literal.
+func quoteGVariant(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// runCommand runs cmd, returning its stdout, and includes its stderr in the returned error.
+func runCommand(cmd *exec.Cmd) (stdout string, err error) {
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	if err = cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(errBuf.String()); len(msg) != 0 {
+			return "", fmt.Errorf("%s: %w: %s", cmd.Path, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", cmd.Path, err)
+	}
+	return outBuf.String(), nil
+}
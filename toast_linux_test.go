@@ -0,0 +1,697 @@
+//go:build linux && !android
+
+package toast
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestPush(t *testing.T) {
+	srv := startFakeNotificationServer(t)
+
+	checkErr(t, Push("test_message"))
+	checkErr(t, Push("test_message", WithTitle("test_title"), WithAudio(MessageNewInstant)))
+
+	calls := srv.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 Notify calls, got %d", len(calls))
+	}
+	if calls[0].Summary != "GO APP" || calls[0].Body != "test_message" || calls[0].AppName != "GO APP" {
+		t.Errorf("unexpected first call: %+v", calls[0])
+	}
+	if _, ok := calls[0].Hints["sound-name"]; ok {
+		t.Errorf("unexpected sound-name hint: %+v", calls[0].Hints)
+	}
+	if calls[1].Summary != "test_title" || calls[1].Body != "test_message" {
+		t.Errorf("unexpected second call: %+v", calls[1])
+	}
+	if v, ok := calls[1].Hints["sound-name"]; !ok || v.Value() != string(MessageNewInstant) {
+		t.Errorf("expected sound-name hint %q, got %+v", MessageNewInstant, calls[1].Hints)
+	}
+}
+
+func TestPush_portableOptions(t *testing.T) {
+	srv := startFakeNotificationServer(t)
+
+	checkErr(t, Push("test_message", WithImage("/tmp/image.png"), WithSilent(true), WithSubtitle("test_subtitle")))
+	calls := srv.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 Notify call, got %d", len(calls))
+	}
+	if v, ok := calls[0].Hints["image-path"]; !ok || v.Value() != "/tmp/image.png" {
+		t.Errorf("expected image-path hint, got %+v", calls[0].Hints)
+	}
+	if v, ok := calls[0].Hints["suppress-sound"]; !ok || v.Value() != true {
+		t.Errorf("expected suppress-sound hint, got %+v", calls[0].Hints)
+	}
+
+	err := Push("test_message", WithSubtitle("test_subtitle"), WithStrict())
+	if !errors.Is(err, ErrOptionUnsupported) || !strings.Contains(err.Error(), "WithSubtitle") {
+		t.Errorf("expected WithSubtitle to be unsupported, got %v", err)
+	}
+	if calls = srv.Calls(); len(calls) != 1 {
+		t.Errorf("expected no Notify call in strict mode, got %d", len(calls)-1)
+	}
+}
+
+func TestPush_timeout(t *testing.T) {
+	srv := startFakeNotificationServer(t)
+
+	checkErr(t, Push("test_message", WithTimeout(1500*time.Millisecond)))
+	checkErr(t, Push("test_message", WithTimeout(time.Second), WithPersistent()))
+	checkErr(t, Push("test_message", WithPersistent(), WithTimeout(2*time.Second)))
+
+	calls := srv.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 Notify calls, got %d", len(calls))
+	}
+	for i, expected := range []int32{1500, 0, 2000} {
+		if calls[i].ExpireTimeout != expected {
+			t.Errorf("call %d: expected expire_timeout %d, got %d", i, expected, calls[i].ExpireTimeout)
+		}
+	}
+}
+
+func TestPushWithCommand(t *testing.T) {
+	stubNoSessionBus(t)
+
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	writeScript(t, filepath.Join(dir, "notify-send"), `[ "$1" = --help ] && echo "  -p, --print-id" && exit
+printf '%s\n' "$@" > `+argsFile+`; echo 7`)
+	t.Setenv("PATH", dir)
+
+	h, err := Send("-test_message",
+		WithTitle("test_title"),
+		WithAppID("test_app"),
+		WithIcon("dialog-information"),
+		WithUrgency(Critical),
+		WithTimeout(3*time.Second),
+		WithAudio(Bell),
+	)
+	checkErr(t, err)
+	if h.ID() != "7" {
+		t.Errorf("expected id 7, got %q", h.ID())
+	}
+
+	bs, err := os.ReadFile(argsFile)
+	checkErr(t, err)
+	expected := []string{
+		"--print-id",
+		"--app-name=test_app",
+		"--urgency=critical",
+		"--icon=dialog-information",
+		"--expire-time=3000",
+		"--hint=string:sound-name:bell",
+		"--",
+		"test_title",
+		"-test_message",
+	}
+	if got := strings.Split(strings.TrimSuffix(string(bs), "\n"), "\n"); strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected args %q, got %q", expected, got)
+	}
+
+	checkErr(t, h.Update(WithMessage("test_update")))
+	bs, err = os.ReadFile(argsFile)
+	checkErr(t, err)
+	if args := string(bs); !strings.Contains(args, "--replace-id=7\n") || !strings.HasSuffix(args, "test_update\n") {
+		t.Errorf("expected the update to replace notification 7, got %q", args)
+	}
+	if err = h.Close(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected %v, got %v", ErrNotSupported, err)
+	}
+
+	writeScript(t, filepath.Join(dir, "notify-send"), `echo "no server" >&2; exit 1`)
+	err = Push("test_message")
+	if err == nil || !strings.Contains(err.Error(), "no server") {
+		t.Errorf("expected error with stderr, got %v", err)
+	}
+}
+
+// notify-send before libnotify 0.7.10 has no --print-id nor --replace-id, and exits on unknown options.
+func TestPushWithCommand_legacy(t *testing.T) {
+	stubNoSessionBus(t)
+
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	writeScript(t, filepath.Join(dir, "notify-send"), `for arg; do
+	case "$arg" in
+	--help) echo "  -u, --urgency=LEVEL"; exit ;;
+	--) break ;;
+	--app-name=*|--urgency=*|--icon=*|--expire-time=*|--hint=*) ;;
+	*) echo "Unknown option $arg" >&2; exit 1 ;;
+	esac
+done
+printf '%s\n' "$@" > `+argsFile)
+	t.Setenv("PATH", dir)
+
+	h, err := Send("test_message", WithTitle("test_title"), WithUrgency(Low))
+	checkErr(t, err)
+	if len(h.ID()) != 0 {
+		t.Errorf("expected no id, got %q", h.ID())
+	}
+	checkErr(t, h.Update(WithMessage("test_update")))
+	bs, err := os.ReadFile(argsFile)
+	checkErr(t, err)
+	if args := string(bs); args != "--app-name=GO APP\n--urgency=low\n--\ntest_title\ntest_update\n" {
+		t.Errorf("unexpected args %q", args)
+	}
+
+	if err = Push("test_message", WithNotificationID("3"), WithStrict()); !errors.Is(err, ErrOptionUnsupported) {
+		t.Errorf("expected %v, got %v", ErrOptionUnsupported, err)
+	}
+}
+
+func TestExpireTimeout(t *testing.T) {
+	for _, tt := range []struct {
+		n        Notification
+		expected int32
+	}{
+		{Notification{}, -1},
+		{Notification{Persistent: true, Timeout: time.Second}, 0},
+		{Notification{Timeout: 3 * time.Second}, 3000},
+		{Notification{Timeout: time.Nanosecond}, 1},
+		{Notification{Timeout: 1500 * time.Microsecond}, 2},
+		{Notification{Timeout: 30 * 24 * time.Hour}, math.MaxInt32},
+		{Notification{Timeout: math.MaxInt64}, math.MaxInt32},
+	} {
+		if timeout := expireTimeout(&tt.n); timeout != tt.expected {
+			t.Errorf("%v: expected %d, got %d", tt.n.Timeout, tt.expected, timeout)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	srv := startFakeNotificationServer(t)
+
+	h, err := Send("test_message", WithTitle("test_title"))
+	checkErr(t, err)
+	if h.ID() != "1" {
+		t.Errorf("expected id 1, got %q", h.ID())
+	}
+	checkErr(t, h.Update(WithMessage("test_update")))
+	if h.ID() != "1" {
+		t.Errorf("expected id 1 after the update, got %q", h.ID())
+	}
+	checkErr(t, h.Close())
+
+	calls := srv.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 Notify calls, got %d", len(calls))
+	}
+	if calls[1].ReplacesID != 1 || calls[1].Summary != "test_title" || calls[1].Body != "test_update" {
+		t.Errorf("unexpected update: %+v", calls[1])
+	}
+	if closed := srv.Closed(); len(closed) != 1 || closed[0] != 1 {
+		t.Errorf("expected notification 1 to be closed, got %v", closed)
+	}
+}
+
+func TestHandle_SetProgress(t *testing.T) {
+	srv := startFakeNotificationServer(t)
+
+	h, err := Send("test_message", WithProgress("test_title", 0.1, "1/10", "downloading"))
+	checkErr(t, err)
+	checkErr(t, h.SetProgress(0.5))
+	checkErr(t, h.SetProgress(-1))
+
+	calls := srv.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 Notify calls, got %d", len(calls))
+	}
+	for i, expected := range []int32{10, 50} {
+		if v, ok := calls[i].Hints["value"]; !ok || v.Value() != expected {
+			t.Errorf("call %d: expected value hint %d, got %+v", i, expected, calls[i].Hints)
+		}
+	}
+	if calls[1].ReplacesID != 1 {
+		t.Errorf("expected the progress to replace notification 1, got %+v", calls[1])
+	}
+	if _, ok := calls[2].Hints["value"]; ok {
+		t.Errorf("unexpected value hint for an indeterminate progress: %+v", calls[2].Hints)
+	}
+
+	h, err = Send("test_message")
+	checkErr(t, err)
+	if err = h.SetProgress(0.5); err == nil {
+		t.Error("expected an error without WithProgress")
+	}
+}
+
+func TestWithAction(t *testing.T) {
+	srv := startFakeNotificationServer(t)
+
+	events := make(chan ActionEvent, 4)
+	callback := func(ev ActionEvent) { events <- ev }
+	closed := make(chan struct{})
+	h, err := Send("test_message",
+		WithAction(DefaultAction, "", callback),
+		WithProtocolAction("Open Maps", "bingmaps:?q=sushi"),
+		WithAction("open", "Open", callback),
+		WithAction("snooze", "Snooze", nil),
+		WithOnClose(func() { close(closed) }),
+	)
+	checkErr(t, err)
+	if actions := srv.Calls()[0].Actions; strings.Join(actions, "|") != "default||open|Open" {
+		t.Errorf("unexpected actions: %q", actions)
+	}
+
+	srv.InvokeAction(t, 2, "open")
+	srv.InvokeAction(t, 1, "open")
+	srv.InvokeAction(t, 1, DefaultAction)
+	for _, expected := range []string{"open", DefaultAction} {
+		select {
+		case ev := <-events:
+			if ev.ActionID != expected || ev.NotificationID != h.ID() {
+				t.Errorf("expected action %q of notification %s, got %+v", expected, h.ID(), ev)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for action %q", expected)
+		}
+	}
+
+	srv.CloseNotificationWithReason(t, 1, 2)
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the close callback")
+	}
+	waitForListeners(t, 0)
+	srv.InvokeAction(t, 1, "open")
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected action after the notification was closed: %+v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWithTextInput(t *testing.T) {
+	srv := startFakeNotificationServer(t)
+	srv.SetCapabilities("actions", inlineReply)
+
+	events := make(chan ActionEvent, 4)
+	opts := []NotificationOption{
+		WithTextInput("reply", "Type a reply"),
+		WithAction("reply", "Send", func(ev ActionEvent) { events <- ev }),
+	}
+	_, err := Send("test_message", opts...)
+	checkErr(t, err)
+	call := srv.Calls()[0]
+	if actions := call.Actions; strings.Join(actions, "|") != "inline-reply|Send" {
+		t.Errorf("unexpected actions: %q", actions)
+	}
+	if v, ok := call.Hints["x-kde-reply-placeholder-text"]; !ok || v.Value() != "Type a reply" {
+		t.Errorf("expected the placeholder hint, got %+v", call.Hints)
+	}
+
+	srv.Reply(t, 1, "test_reply")
+	select {
+	case ev := <-events:
+		if ev.ActionID != "reply" || ev.Inputs["reply"] != "test_reply" {
+			t.Errorf("unexpected event: %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reply")
+	}
+	srv.CloseNotificationWithReason(t, 1, 2)
+	waitForListeners(t, 0)
+
+	// a plain action without the capability
+	srv.SetCapabilities("actions")
+	_, err = Send("test_message", opts...)
+	checkErr(t, err)
+	if actions := srv.Calls()[1].Actions; strings.Join(actions, "|") != "reply|Send" {
+		t.Errorf("unexpected actions: %q", actions)
+	}
+	srv.CloseNotificationWithReason(t, 2, 2)
+	waitForListeners(t, 0)
+}
+
+func TestWithIconImage_imageData(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	srv := startFakeNotificationServer(t)
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(2, 1, color.NRGBA{R: 0xff, G: 0x80, A: 0xff})
+
+	checkErr(t, Push("test_message", WithIconImage(img)))
+	call := srv.Calls()[0]
+	if len(call.AppIcon) != 0 {
+		t.Errorf("expected no app_icon, got %q", call.AppIcon)
+	}
+	var data imageData
+	checkErr(t, call.Hints["image-data"].Store(&data))
+	if data.Width != 3 || data.Height != 2 || data.Rowstride != 12 || !data.HasAlpha || data.Channels != 4 || len(data.Data) != 24 {
+		t.Errorf("unexpected image-data: %+v", data)
+	}
+	if pixel := data.Data[20:]; string(pixel) != "\xff\x80\x00\xff" {
+		t.Errorf("unexpected pixel %v", pixel)
+	}
+
+	// scaled down to the size of the icons
+	checkErr(t, Push("test_message", WithIconImage(image.NewNRGBA(image.Rect(0, 0, 1024, 512)))))
+	checkErr(t, srv.Calls()[1].Hints["image-data"].Store(&data))
+	if data.Width != freedesktopIconSize || data.Height != freedesktopIconSize/2 || int(data.Rowstride)*int(data.Height) != len(data.Data) {
+		t.Errorf("unexpected image-data: %dx%d", data.Width, data.Height)
+	}
+
+	// not decodable, written to a file instead
+	checkErr(t, Push("test_message", WithIconRaw([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>"))))
+	call = srv.Calls()[2]
+	if _, ok := call.Hints["image-data"]; ok || filepath.Ext(call.AppIcon) != ".svg" {
+		t.Errorf("expected an svg file, got %q %+v", call.AppIcon, call.Hints)
+	}
+}
+
+func waitForListeners(t *testing.T, expected int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		_listenersMu.Lock()
+		count := len(_listeners)
+		_listenersMu.Unlock()
+		if count == expected {
+			return
+		}
+	}
+	t.Fatalf("expected %d listeners", expected)
+}
+
+func TestCapabilities(t *testing.T) {
+	srv := startFakeNotificationServer(t)
+	srv.SetCapabilities("actions", "body-markup", "icon-static", "sound")
+
+	expected := Caps{Actions: true, BodyMarkup: true, Images: true, Sounds: true, MaxActions: maxActions}
+	if caps := Capabilities(); caps != expected {
+		t.Errorf("expected %+v, got %+v", expected, caps)
+	}
+
+	stubNoSessionBus(t)
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "notify-send"), "exit 0")
+	t.Setenv("PATH", dir)
+	if caps := Capabilities(); caps != (Caps{Images: true}) {
+		t.Errorf("expected the capabilities of notify-send, got %+v", caps)
+	}
+}
+
+func TestConnectListener(t *testing.T) {
+	release := make(chan struct{})
+	connect := connectSessionBus
+	connectSessionBus = func(...dbus.ConnOption) (*dbus.Conn, error) {
+		<-release
+		return nil, errors.New("no session bus")
+	}
+	defer func() {
+		close(release)
+		connectSessionBus = connect
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := notifyAndListen(ctx, NewNotification("test_message", WithAction(DefaultAction, "", func(ActionEvent) {})))
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestHandle_context(t *testing.T) {
+	srv := startFakeNotificationServer(t)
+	h, err := Send("test_message")
+	checkErr(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err = h.UpdateContext(ctx, WithMessage("test_update")); err == nil {
+		t.Error("expected the update to give up")
+	}
+	if err = h.CloseContext(ctx); err == nil {
+		t.Error("expected the close to give up")
+	}
+	checkErr(t, h.UpdateContext(context.Background(), WithMessage("test_update")))
+	checkErr(t, h.CloseContext(context.Background()))
+	if calls := srv.Calls(); len(calls) != 2 || calls[1].Body != "test_update" {
+		t.Errorf("unexpected calls %+v", calls)
+	}
+}
+
+func TestPushContext(t *testing.T) {
+	startFakeNotificationServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := PushContext(ctx, "test_message"); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	sleep, err := exec.LookPath("sleep")
+	checkErr(t, err)
+	stubNoSessionBus(t)
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "notify-send"), "exec "+sleep+" 10")
+	t.Setenv("PATH", dir)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err = PushContext(ctx, "test_message"); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected notify-send to be killed, took %v", elapsed)
+	}
+}
+
+func TestPushWithCommand_gdbus(t *testing.T) {
+	gdbus, err := exec.LookPath("gdbus")
+	if err != nil {
+		t.Skip("gdbus not found:", err)
+	}
+	srv := startFakeNotificationServer(t)
+	stubNoSessionBus(t)
+
+	dir := t.TempDir()
+	checkErr(t, os.Symlink(gdbus, filepath.Join(dir, "gdbus")))
+	t.Setenv("PATH", dir)
+
+	title := `it's a "test" \ $(id)`
+	message := "line1\nline2\ttab"
+	h, err := Send(message, WithTitle(title), WithUrgency(Low), WithAudio(Complete))
+	checkErr(t, err)
+	if h.ID() != "1" {
+		t.Errorf("expected id 1, got %q", h.ID())
+	}
+	checkErr(t, h.Close())
+	if closed := srv.Closed(); len(closed) != 1 || closed[0] != 1 {
+		t.Errorf("expected notification 1 to be closed, got %v", closed)
+	}
+
+	calls := srv.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 Notify call, got %d", len(calls))
+	}
+	if calls[0].Summary != title || calls[0].Body != message {
+		t.Errorf("expected %q/%q, got %q/%q", title, message, calls[0].Summary, calls[0].Body)
+	}
+	if v := calls[0].Hints["urgency"].Value(); v != byte(0) {
+		t.Errorf("expected urgency 0, got %v", v)
+	}
+	if v := calls[0].Hints["sound-name"].Value(); v != string(Complete) {
+		t.Errorf("expected sound-name %q, got %v", Complete, v)
+	}
+	if calls[0].ExpireTimeout != -1 {
+		t.Errorf("expected expire_timeout -1, got %d", calls[0].ExpireTimeout)
+	}
+}
+
+func stubNoSessionBus(t *testing.T) {
+	connect := connectSessionBus
+	connectSessionBus = func(...dbus.ConnOption) (*dbus.Conn, error) {
+		return nil, errors.New("no session bus")
+	}
+	t.Cleanup(func() { connectSessionBus = connect })
+}
+
+func writeScript(t *testing.T, name, body string) {
+	t.Helper()
+	checkErr(t, os.WriteFile(name, []byte("#!/bin/sh\n"+body+"\n"), 0700))
+}
+
+type fakeNotifyCall struct {
+	AppName       string
+	ReplacesID    uint32
+	AppIcon       string
+	Summary       string
+	Body          string
+	Actions       []string
+	Hints         map[string]dbus.Variant
+	ExpireTimeout int32
+}
+
+// fakeNotificationServer implements org.freedesktop.Notifications on a private session bus.
+type fakeNotificationServer struct {
+	conn *dbus.Conn
+
+	mu     sync.Mutex
+	caps   []string
+	lastID uint32
+	calls  []fakeNotifyCall
+	closed []uint32
+}
+
+func (s *fakeNotificationServer) Notify(appName string, replacesID uint32, appIcon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, expireTimeout int32) (uint32, *dbus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, fakeNotifyCall{
+		AppName:       appName,
+		ReplacesID:    replacesID,
+		AppIcon:       appIcon,
+		Summary:       summary,
+		Body:          body,
+		Actions:       actions,
+		Hints:         hints,
+		ExpireTimeout: expireTimeout,
+	})
+	if replacesID != 0 {
+		return replacesID, nil
+	}
+	s.lastID++
+	return s.lastID, nil
+}
+
+func (s *fakeNotificationServer) SetCapabilities(caps ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.caps = caps
+}
+
+func (s *fakeNotificationServer) GetCapabilities() ([]string, *dbus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{"body"}, s.caps...), nil
+}
+
+func (s *fakeNotificationServer) CloseNotification(id uint32) *dbus.Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = append(s.closed, id)
+	return nil
+}
+
+// InvokeAction emits the ActionInvoked signal, as if the user clicked the action.
+func (s *fakeNotificationServer) InvokeAction(t *testing.T, id uint32, key string) {
+	t.Helper()
+	checkErr(t, s.conn.Emit(dbusNotificationsPath, dbusNotificationsInterface+".ActionInvoked", id, key))
+}
+
+// Reply emits the NotificationReplied signal, as if the user sent the text inline.
+func (s *fakeNotificationServer) Reply(t *testing.T, id uint32, text string) {
+	t.Helper()
+	checkErr(t, s.conn.Emit(dbusNotificationsPath, dbusNotificationsInterface+".NotificationReplied", id, text))
+}
+
+// CloseNotificationWithReason emits the NotificationClosed signal.
+func (s *fakeNotificationServer) CloseNotificationWithReason(t *testing.T, id, reason uint32) {
+	t.Helper()
+	checkErr(t, s.conn.Emit(dbusNotificationsPath, dbusNotificationsInterface+".NotificationClosed", id, reason))
+}
+
+func (s *fakeNotificationServer) Closed() []uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]uint32(nil), s.closed...)
+}
+
+func (s *fakeNotificationServer) Calls() []fakeNotifyCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fakeNotifyCall(nil), s.calls...)
+}
+
+// startFakeNotificationServer spawns a private dbus-daemon, points DBUS_SESSION_BUS_ADDRESS at it
+// and registers a fakeNotificationServer as org.freedesktop.Notifications.
+func startFakeNotificationServer(t *testing.T) *fakeNotificationServer {
+	t.Helper()
+	address := startSessionBus(t)
+
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	srv := &fakeNotificationServer{conn: conn}
+	if err = conn.Export(srv, dbusNotificationsPath, dbusNotificationsInterface); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := conn.RequestName(dbusNotificationsName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("failed to own %s: %v", dbusNotificationsName, reply)
+	}
+	return srv
+}
+
+const testBusConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:dir=%DIR%</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+func startSessionBus(t *testing.T) (address string) {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not found:", err)
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "session.conf")
+	if err = os.WriteFile(config, []byte(strings.ReplaceAll(testBusConfig, "%DIR%", dir)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address=1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	address, err = bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	address = strings.TrimSpace(address)
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", address)
+	return address
+}
@@ -0,0 +1,38 @@
+//go:build !darwin && !windows && (android || !linux) && !(js && wasm)
+
+package toast
+
+import (
+	"context"
+	"runtime"
+)
+
+func defaultNotifier() (Notifier, error) {
+	return unsupported{}, nil
+}
+
+func init() {
+	Register("unsupported", func() (Notifier, error) { return unsupported{}, nil })
+}
+
+func newNotification(message string, opts ...NotificationOption) *Notification {
+	n := &Notification{
+		Title:   "GO APP",
+		Message: message,
+	}
+	for _, fn := range opts {
+		fn(n)
+	}
+	return n
+}
+
+// unsupported is the default backend on platforms without a notification system.
+type unsupported struct{}
+
+func (unsupported) Notify(context.Context, *Notification) error {
+	return &UnsupportedPlatformError{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH}
+}
+
+func (unsupported) Capabilities(context.Context) (Caps, error) {
+	return Caps{}, nil
+}
@@ -0,0 +1,21 @@
+//go:build !darwin && !windows && (android || !linux) && !(js && wasm)
+
+package toast
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestUnsupported(t *testing.T) {
+	err := unsupported{}.Notify(context.Background(), NewNotification("test_message"))
+	var target *UnsupportedPlatformError
+	if !errors.As(err, &target) || target.GOOS != runtime.GOOS || target.GOARCH != runtime.GOARCH {
+		t.Errorf("expected an UnsupportedPlatformError for %s/%s, got %v", runtime.GOOS, runtime.GOARCH, err)
+	}
+	if !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("expected %v to match ErrUnsupportedPlatform", err)
+	}
+}
@@ -0,0 +1,133 @@
+package toast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNotification_JSON(t *testing.T) {
+	n := Notification{
+		ID:        "7",
+		Title:     "test_title",
+		Message:   "test_message",
+		Audio:     Bell,
+		Actions:   []Action{{Type: "protocol", Label: "Open Maps", Arguments: "bingmaps:?q=sushi"}},
+		AppID:     "test_app",
+		Urgency:   Critical,
+		Timeout:   90 * time.Second,
+		Timestamp: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+		Vibrate:   []int{200, 100},
+	}
+	bs, err := json.Marshal(n)
+	checkErr(t, err)
+	expected := `{"id":"7","title":"test_title","message":"test_message","audio":"bell",` +
+		`"actions":[{"type":"protocol","label":"Open Maps","arguments":"bingmaps:?q=sushi"}],` +
+		`"app_id":"test_app","urgency":"critical","vibrate":[200,100],` +
+		`"timeout":"1m30s","timestamp":"2021-06-01T12:00:00Z"}`
+	if string(bs) != expected {
+		t.Errorf("expected %s, got %s", expected, bs)
+	}
+
+	var decoded Notification
+	checkErr(t, json.Unmarshal(bs, &decoded))
+	if !reflect.DeepEqual(decoded, n) {
+		t.Errorf("expected %+v, got %+v", n, decoded)
+	}
+
+	bs, err = json.Marshal(&Notification{Title: "test_title"})
+	checkErr(t, err)
+	if expected = `{"title":"test_title","message":""}`; string(bs) != expected {
+		t.Errorf("expected %s, got %s", expected, bs)
+	}
+	if err = json.Unmarshal([]byte(`{"timeout":"soon"}`), &decoded); err == nil {
+		t.Error("expected an error for an invalid timeout")
+	}
+}
+
+func TestNotification_YAML(t *testing.T) {
+	n := Notification{
+		ID:        "7",
+		Title:     "test_title",
+		Message:   "test_message",
+		Audio:     Bell,
+		Actions:   []Action{{Type: "protocol", Label: "Open Maps", Arguments: "bingmaps:?q=sushi"}},
+		AppID:     "test_app",
+		Urgency:   Critical,
+		Timeout:   90 * time.Second,
+		Timestamp: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+		Vibrate:   []int{200, 100},
+	}
+	bs, err := yaml.Marshal(n)
+	checkErr(t, err)
+	expected := `id: "7"
+title: test_title
+message: test_message
+audio: bell
+actions:
+    - type: protocol
+      label: Open Maps
+      arguments: bingmaps:?q=sushi
+app_id: test_app
+urgency: critical
+timeout: 1m30s
+timestamp: 2021-06-01T12:00:00Z
+vibrate:
+    - 200
+    - 100
+`
+	if string(bs) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, bs)
+	}
+
+	var decoded Notification
+	checkErr(t, yaml.Unmarshal(bs, &decoded))
+	if !reflect.DeepEqual(decoded, n) {
+		t.Errorf("expected %+v, got %+v", n, decoded)
+	}
+}
+
+func TestNotification_encodingLosesStrict(t *testing.T) {
+	n := NewNotification("test_message", WithStrict(), WithUrgency(Critical))
+	bs, err := json.Marshal(n)
+	checkErr(t, err)
+	var decoded Notification
+	checkErr(t, json.Unmarshal(bs, &decoded))
+	if decoded.strict || decoded.options != nil || decoded.Urgency != Critical {
+		t.Errorf("expected the fields without WithStrict, got %+v", decoded)
+	}
+
+	bs, err = yaml.Marshal(n)
+	checkErr(t, err)
+	decoded = Notification{}
+	checkErr(t, yaml.Unmarshal(bs, &decoded))
+	if decoded.strict || decoded.options != nil || decoded.Urgency != Critical {
+		t.Errorf("expected the fields without WithStrict, got %+v", decoded)
+	}
+}
+
+func TestUnsupportedPlatformError(t *testing.T) {
+	var err error = &UnsupportedPlatformError{GOOS: "plan9", GOARCH: "386"}
+	if expected := "toast: unsupported platform: plan9/386"; err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err)
+	}
+	err = fmt.Errorf("push: %w", err)
+	if !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("expected %v to match ErrUnsupportedPlatform", err)
+	}
+	var target *UnsupportedPlatformError
+	if !errors.As(err, &target) || target.GOOS != "plan9" || target.GOARCH != "386" {
+		t.Errorf("expected the UnsupportedPlatformError, got %v", target)
+	}
+}
+
+func checkErr(t *testing.T, err error) {
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -4,6 +4,7 @@ package toast
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/rand"
 	"os"
@@ -18,213 +19,166 @@ import (
 	"unsafe"
 )
 
-// WithAppID
-//
-// The name of your app. This value shows up in Windows 10's Action Centre, so make it
-// something readable for your users. It can contain spaces, however special characters
-// (eg. é) are not supported.
-func WithAppID(appID string) NotificationOption {
-	return func(n *notification) {
-		n.AppID = appID
-	}
+// defaultNotifier calls the Windows Runtime directly, with PowerShell as a fallback.
+func defaultNotifier() (Notifier, error) {
+	return Chain(registered("winrt"), registered("powershell")), nil
 }
 
-// WithIcon
-//
-// An optional path to an image on the OS to display to the left of the title & message.
-func WithIcon(pathIcon string) NotificationOption {
-	return func(n *notification) {
-		n.Icon = pathIcon
-	}
+func init() {
+	Register("powershell", func() (Notifier, error) { return powershell{}, nil })
 }
 
-func WithIconRaw(raw []byte) NotificationOption {
-	return func(n *notification) {
-		randBytes := make([]byte, 4)
-		_r.Read(randBytes)
-		n._tmpIconFilename = filepath.Join(os.TempDir(), fmt.Sprintf("go-toast-logo-%x.png", randBytes))
-		if err := os.WriteFile(n._tmpIconFilename, raw, 0600); err != nil {
-			return
-		}
-		n.Icon = n._tmpIconFilename
+func newNotification(message string, opts ...NotificationOption) *Notification {
+	n := &Notification{
+		Title:          "GO APP",
+		Message:        message,
+		Audio:          Silent,
+		AppID:          "GO APP",
+		ActivationType: "protocol",
+		Duration:       Short,
+	}
+	for _, fn := range opts {
+		fn(n)
 	}
+	return n
 }
 
-// WithActivationType
-//
-// The type of notification level action (like Action)
-func WithActivationType(activationType string) NotificationOption {
-	return func(n *notification) {
-		n.ActivationType = activationType
+// toastGroup is the Group of every toast, the ID of a notification is its Tag.
+const toastGroup = "go-toast"
+
+// powershell displays notifications by running a generated script with PowerShell.
+type powershell struct{}
+
+func (powershell) Notify(ctx context.Context, n *Notification) error {
+	if err := validateScenario(n); err != nil {
+		return err
+	}
+	if len(n.ID) == 0 {
+		n.ID = toastTag()
+	}
+	content, err := powershellScript(n)
+	if err != nil {
+		return err
 	}
-}
 
-// WithActivationArguments
-//
-// // The activation/action arguments (invoked when the user clicks the notification)
-func WithActivationArguments(activationArguments string) NotificationOption {
-	return func(n *notification) {
-		n.ActivationArguments = activationArguments
+	if n.hasCallbacks() {
+		return showAndListen(ctx, n, content)
 	}
+	return runPowerShell(ctx, content)
 }
 
-// WithProtocolAction
-//
-// Defines an actionable button.
-// See https://msdn.microsoft.com/en-us/windows/uwp/controls-and-patterns/tiles-and-notifications-adaptive-interactive-toasts for more info.
-//
-// Only protocol type action buttons are actually useful, as there's no way of receiving feedback from the
-// user's choice. Examples of protocol type action buttons include: "bingmaps:?q=sushi" to open up Windows 10's
-// maps app with a pre-populated search field set to "sushi".
-//
-//     Action{"protocol", "Open Maps", "bingmaps:?q=sushi"}
-func WithProtocolAction(label string, arguments ...string) NotificationOption {
-	return func(n *notification) {
-		if len(n.Actions) == 0 {
-			n.Actions = make([]Action, 0, 5)
-		}
-		if len(n.Actions) == 5 {
-			return
-		}
-		if len(arguments) == 0 {
-			arguments = []string{""}
-		}
-		n.Actions = append(n.Actions, Action{
-			Type:      "protocol",
-			Label:     escapeNotificationString(label),
-			Arguments: arguments[0],
-		})
-	}
+func (powershell) Capabilities(context.Context) (Caps, error) {
+	return Caps{
+		Actions:     true,
+		Images:      true,
+		Sounds:      true,
+		Persistence: true,
+		Inputs:      true,
+		Progress:    true,
+		MaxActions:  maxActions,
+	}, nil
 }
 
-// WithAudioLoop
-//
-// Whether to loop the audio (default false)
-func WithAudioLoop(b bool) NotificationOption {
-	return func(n *notification) {
-		n.Loop = b
-	}
+// the options mapped to the toast XML
+var powershellOptions = []string{
+	"WithAudio", "WithAction", "WithAppID", "WithIcon", "WithIconRaw", "WithIconImage", "WithIconFS", "WithIconReader", "WithImage", "WithSubtitle",
+	"WithInlineImage", "WithHeroImage", "WithIconCrop", "WithAttribution", "WithProgress",
+	"WithTextInput", "WithSelection", "WithScenario",
+	"WithActivationType", "WithActivationArguments", "WithProtocolAction", "WithAudioLoop", "WithDuration",
+	"WithTimestamp", "WithUrgency", "WithTimeout", "WithPersistent", "WithSilent", "WithNotificationID",
 }
 
-// WithDuration
-//
-// How long the notification should show up for (short/long)
-func WithDuration(nd NotificationDuration) NotificationOption {
-	return func(n *notification) {
-		n.Duration = nd
-	}
+func (powershell) droppedOptions(_ context.Context, n *Notification) []string {
+	return n.dropped(powershellOptions...)
 }
 
-func WithLongDuration() NotificationOption {
-	return func(n *notification) {
-		n.Duration = Long
-	}
+func (powershell) maxIconSize() int {
+	return windowsIconSize
 }
 
-func WithShortDuration() NotificationOption {
-	return func(n *notification) {
-		n.Duration = Short
+// updateProgress updates the data bound to the progress bar of the toast.
+func (powershell) updateProgress(ctx context.Context, n *Notification) error {
+	var script strings.Builder
+	script.WriteString(`
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.UI.Notifications.NotificationData, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$go_data = New-Object Windows.UI.Notifications.NotificationData
+`)
+	for key, value := range quotedProgressData(n.Progress) {
+		script.WriteString("$go_data.Values[" + key + "] = " + value + "\n")
 	}
+	// zero updates whatever the sequence number of the toast
+	script.WriteString(`$go_data.SequenceNumber = 0
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(` + quotePowerShell(appID(n)) + `).Update($go_data, ` +
+		quotePowerShell(n.ID) + `, ` + quotePowerShell(toastGroup) + `) | Out-Null
+`)
+	return runPowerShell(ctx, []byte(script.String()))
 }
 
-type NotificationDuration string
-
-const (
-	Short NotificationDuration = "short"
-	Long  NotificationDuration = "long"
-)
-
-const (
-	Silent         Audio = "silent"
-	Default        Audio = "ms-winsoundevent:Notification.Default"
-	IM             Audio = "ms-winsoundevent:Notification.IM"
-	Mail           Audio = "ms-winsoundevent:Notification.Mail"
-	Reminder       Audio = "ms-winsoundevent:Notification.Reminder"
-	SMS            Audio = "ms-winsoundevent:Notification.SMS"
-	LoopingAlarm   Audio = "ms-winsoundevent:Notification.Looping.Alarm"
-	LoopingAlarm2  Audio = "ms-winsoundevent:Notification.Looping.Alarm2"
-	LoopingAlarm3  Audio = "ms-winsoundevent:Notification.Looping.Alarm3"
-	LoopingAlarm4  Audio = "ms-winsoundevent:Notification.Looping.Alarm4"
-	LoopingAlarm5  Audio = "ms-winsoundevent:Notification.Looping.Alarm5"
-	LoopingAlarm6  Audio = "ms-winsoundevent:Notification.Looping.Alarm6"
-	LoopingAlarm7  Audio = "ms-winsoundevent:Notification.Looping.Alarm7"
-	LoopingAlarm8  Audio = "ms-winsoundevent:Notification.Looping.Alarm8"
-	LoopingAlarm9  Audio = "ms-winsoundevent:Notification.Looping.Alarm9"
-	LoopingAlarm10 Audio = "ms-winsoundevent:Notification.Looping.Alarm10"
-	LoopingCall    Audio = "ms-winsoundevent:Notification.Looping.Call"
-	LoopingCall2   Audio = "ms-winsoundevent:Notification.Looping.Call2"
-	LoopingCall3   Audio = "ms-winsoundevent:Notification.Looping.Call3"
-	LoopingCall4   Audio = "ms-winsoundevent:Notification.Looping.Call4"
-	LoopingCall5   Audio = "ms-winsoundevent:Notification.Looping.Call5"
-	LoopingCall6   Audio = "ms-winsoundevent:Notification.Looping.Call6"
-	LoopingCall7   Audio = "ms-winsoundevent:Notification.Looping.Call7"
-	LoopingCall8   Audio = "ms-winsoundevent:Notification.Looping.Call8"
-	LoopingCall9   Audio = "ms-winsoundevent:Notification.Looping.Call9"
-	LoopingCall10  Audio = "ms-winsoundevent:Notification.Looping.Call10"
-)
-
-// Action
-//
-// Defines an actionable button.
-// See https://msdn.microsoft.com/en-us/windows/uwp/controls-and-patterns/tiles-and-notifications-adaptive-interactive-toasts for more info.
-//
-// Only protocol type action buttons are actually useful, as there's no way of receiving feedback from the
-// user's choice. Examples of protocol type action buttons include: "bingmaps:?q=sushi" to open up Windows 10's
-// maps app with a pre-populated search field set to "sushi".
-//
-//     Action{"protocol", "Open Maps", "bingmaps:?q=sushi"}
-type Action struct {
-	Type      string
-	Label     string
-	Arguments string
+func quotedProgressData(p *Progress) map[string]string {
+	data := progressData(p)
+	quoted := make(map[string]string, len(data))
+	for key, value := range data {
+		quoted[quotePowerShell(key)] = quotePowerShell(value)
+	}
+	return quoted
 }
 
-var _ notifier = (*notification)(nil)
-
-func newNotification(message string, opts ...NotificationOption) *notification {
-	n := &notification{
-		AppID:          "GO APP",
-		Title:          "GO APP",
-		Message:        message,
-		ActivationType: "protocol",
-		Duration:       Short,
-		Audio:          Silent,
-	}
-	for _, fn := range opts {
-		fn(n)
+// Close removes the toast from the Action Center.
+func (powershell) Close(ctx context.Context, n *Notification) error {
+	if len(n.ID) == 0 {
+		return nil
 	}
-	n.AppID = escapeNotificationString(n.AppID)
-	n.Title = escapeNotificationString(n.Title)
-	n.Message = escapeNotificationString(n.Message)
-	return n
+	script := `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.UI.Notifications.ToastNotificationManager]::History.Remove(` +
+		quotePowerShell(n.ID) + `, ` + quotePowerShell(toastGroup) + `, ` + quotePowerShell(appID(n)) + `)
+`
+	return runPowerShell(ctx, []byte(script))
 }
 
-func (n *notification) push() error {
-	content, err := n.template()
+// runPowerShell writes the script to a temporary file and has PowerShell run it,
+func runPowerShell(ctx context.Context, content []byte) error {
+	tmpFilename, err := writeScript(content)
 	if err != nil {
 		return err
 	}
 
-	randBytes := make([]byte, 4)
-	_r.Read(randBytes)
-	tmpFilename := filepath.Join(os.TempDir(), fmt.Sprintf("go-toast-%x.ps1", randBytes))
-
-	if err = os.WriteFile(tmpFilename, content, 0600); err != nil {
-		return err
-	}
-
 	defer func() {
 		_ = os.Remove(tmpFilename)
 	}()
 
-	launch := "(Get-Content -Encoding UTF8 -Path " + tmpFilename + " -Raw) | Invoke-Expression"
-	if len(n._tmpIconFilename) != 0 {
-		launch += "; Start-Sleep -m 50 ; Remove-Item " + n._tmpIconFilename
-	}
-	cmd := exec.Command("PowerShell", "-ExecutionPolicy", "Bypass", launch)
+	return powerShellCommand(ctx, tmpFilename).Run()
+}
+
+func writeScript(content []byte) (tmpFilename string, err error) {
+	randBytes := make([]byte, 4)
+	_r.Read(randBytes)
+	tmpFilename = filepath.Join(os.TempDir(), fmt.Sprintf("go-toast-%x.ps1", randBytes))
+	err = os.WriteFile(tmpFilename, content, 0600)
+	return
+}
+
+func powerShellCommand(ctx context.Context, tmpFilename string) *exec.Cmd {
+	launch := "(Get-Content -Encoding UTF8 -LiteralPath " + quotePowerShell(tmpFilename) + " -Raw) | Invoke-Expression"
+	cmd := exec.CommandContext(ctx, "PowerShell", "-ExecutionPolicy", "Bypass", launch)
 	fixCmd("PowerShell", cmd)
 	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	return cmd.Run()
+	return cmd
+}
+
+// toastTag returns a random Tag for a new toast.
+func toastTag() string {
+	randBytes := make([]byte, 4)
+	_r.Read(randBytes)
+	return fmt.Sprintf("%x", randBytes)
+}
+
+func appID(n *Notification) string {
+	if len(n.AppID) == 0 {
+		return "Windows App"
+	}
+	return n.AppID
 }
 
 var (
@@ -233,49 +187,52 @@ var (
 	_once sync.Once
 )
 
-func (n *notification) template() (content []byte, err error) {
+func powershellScript(n *Notification) (content []byte, err error) {
 	_once.Do(func() {
 		var tplNotification = `
 [Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
 [Windows.UI.Notifications.ToastNotification, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
 [Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+[Windows.UI.Notifications.NotificationData, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
 
-$APP_ID = '{{if .AppID}}{{.AppID}}{{else}}Windows App{{end}}'
-
-$template = @"
-<toast activationType="{{.ActivationType}}" launch="{{.ActivationArguments}}" duration="{{.Duration}}">
-    <visual>
-        <binding template="ToastGeneric">
-            {{if .Icon}}
-            <image placement="appLogoOverride" src="{{.Icon}}" />
-            {{end}}
-            {{if .Title}}
-            <text><![CDATA[{{.Title}}]]></text>
-            {{end}}
-            {{if .Message}}
-            <text><![CDATA[{{.Message}}]]></text>
-            {{end}}
-        </binding>
-    </visual>
-    {{if ne .Audio "silent"}}
-	<audio src="{{.Audio}}" loop="{{.Loop}}" />
-	{{else}}
-	<audio silent="true" />
-	{{end}}
-    {{if .Actions}}
-    <actions>
-        {{range .Actions}}
-        <action activationType="{{.Type}}" content="{{.Label}}" arguments="{{.Arguments}}" />
-        {{end}}
-    </actions>
-    {{end}}
-</toast>
-"@
+$APP_ID = {{.AppID}}
+
+$template = {{.XML}}
 
 $xml = New-Object Windows.Data.Xml.Dom.XmlDocument
 $xml.LoadXml($template)
 $go_toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+$go_toast.Tag = {{.Tag}}
+$go_toast.Group = {{.Group}}
+{{if .Progress}}
+$go_data = New-Object Windows.UI.Notifications.NotificationData
+{{range $key, $value := .Progress}}$go_data.Values[{{$key}}] = {{$value}}
+{{end}}
+$go_data.SequenceNumber = 1
+$go_toast.Data = $go_data
+{{end}}
+{{if .Expiration}}
+$go_toast.ExpirationTime = [DateTimeOffset]::Now.AddMilliseconds({{.Expiration}})
+{{end}}
+{{if .Listen}}
+Register-ObjectEvent -InputObject $go_toast -EventName Activated -SourceIdentifier go_toast_activated | Out-Null
+Register-ObjectEvent -InputObject $go_toast -EventName Dismissed -SourceIdentifier go_toast_dismissed | Out-Null
+{{end}}
 [Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($APP_ID).Show($go_toast)
+{{if .Listen}}
+[Console]::Out.WriteLine('shown')
+$go_event = Wait-Event
+if ($go_event.SourceIdentifier -eq 'go_toast_activated') {
+    $go_activated = [Windows.UI.Notifications.ToastActivatedEventArgs]$go_event.SourceArgs[1]
+    if ($go_activated.UserInput) {
+        foreach ($go_input in $go_activated.UserInput.GetEnumerator()) {
+            [Console]::Out.WriteLine('input ' + [Convert]::ToBase64String([Text.Encoding]::UTF8.GetBytes($go_input.Key)) +
+                ' ' + [Convert]::ToBase64String([Text.Encoding]::UTF8.GetBytes([string]$go_input.Value)))
+        }
+    }
+    [Console]::Out.WriteLine('activated ' + [Convert]::ToBase64String([Text.Encoding]::UTF8.GetBytes($go_activated.Arguments)))
+}
+{{end}}
 `
 
 		_tpl, err = template.New("_tpl").Parse(tplNotification)
@@ -284,51 +241,34 @@ $go_toast = New-Object Windows.UI.Notifications.ToastNotification $xml
 		return nil, err
 	}
 
+	xml, err := toastXML(n).Marshal()
+	if err != nil {
+		return nil, err
+	}
+	data := struct {
+		AppID, XML, Tag, Group string
+		Progress               map[string]string
+		Expiration             int64
+		Listen                 bool
+	}{
+		AppID:  quotePowerShell(appID(n)),
+		XML:    quotePowerShell(string(xml)),
+		Tag:    quotePowerShell(n.ID),
+		Group:  quotePowerShell(toastGroup),
+		Listen: n.hasCallbacks(),
+	}
+	if n.Progress != nil {
+		data.Progress = quotedProgressData(n.Progress)
+	}
+	if n.Timeout > 0 {
+		data.Expiration = n.Timeout.Milliseconds()
+	}
+
 	buf := bytes.NewBuffer(nil)
-	err = _tpl.Execute(buf, n)
+	err = _tpl.Execute(buf, data)
 	return buf.Bytes(), err
 }
 
-type notification struct {
-	// The name of your app. This value shows up in Windows 10's Action Centre, so make it
-	// something readable for your users. It can contain spaces, however special characters
-	// (eg. é) are not supported.
-	AppID string
-
-	// The main title/heading for the notification.
-	Title string
-
-	// The single/multi line message to display for the notification.
-	Message string
-
-	// An optional path to an image on the OS to display to the left of the title & message.
-	Icon             string
-	_tmpIconFilename string
-
-	// The type of notification level action (like Action)
-	ActivationType string
-
-	// The activation/action arguments (invoked when the user clicks the notification)
-	ActivationArguments string
-
-	// Optional action buttons to display below the notification title & message.
-	Actions []Action
-
-	// The audio to play when displaying the notification
-	Audio Audio
-
-	// Whether to loop the audio (default false)
-	Loop bool
-
-	// How long the notification should show up for (short/long)
-	Duration NotificationDuration
-}
-
-func escapeNotificationString(in string) string {
-	noSlash := strings.ReplaceAll(in, "`", "``")
-	return strings.ReplaceAll(noSlash, "\"", "`\"")
-}
-
 // https://pkg.go.dev/golang.org/x/sys/execabs#Command
 func fixCmd(name string, cmd *exec.Cmd) {
 	if filepath.Base(name) == name && !filepath.IsAbs(cmd.Path) {
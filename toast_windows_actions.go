@@ -0,0 +1,120 @@
+//go:build windows
+
+package toast
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+var (
+	// the PowerShell processes listening for the events of a toast, by Tag
+	_listeners   = make(map[string]*exec.Cmd)
+	_listenersMu sync.Mutex
+)
+
+// showAndListen runs the script, which keeps PowerShell running until the toast is activated or dismissed,
+// and reports the activation on its stdout. PowerShell isn't tied to ctx as it outlives the call.
+func showAndListen(ctx context.Context, n *Notification, content []byte) error {
+	tmpFilename, err := writeScript(content)
+	if err != nil {
+		return err
+	}
+	removeScript := func() {
+		_ = os.Remove(tmpFilename)
+	}
+
+	cmd := powerShellCommand(context.Background(), tmpFilename)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		removeScript()
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		removeScript()
+		return err
+	}
+
+	lines := bufio.NewScanner(stdout)
+	shown := make(chan bool, 1)
+	go func() {
+		shown <- lines.Scan() && lines.Text() == "shown"
+	}()
+	select {
+	case ok := <-shown:
+		// the script has been read by now
+		removeScript()
+		if !ok {
+			err = cmd.Wait()
+			return fmt.Errorf("PowerShell: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		removeScript()
+		return ctx.Err()
+	}
+
+	_listenersMu.Lock()
+	if previous, ok := _listeners[n.ID]; ok {
+		// the toast was replaced, its callbacks with it
+		_ = previous.Process.Kill()
+	}
+	_listeners[n.ID] = cmd
+	_listenersMu.Unlock()
+
+	// the callbacks see the notification as it was displayed, even if it is updated meanwhile
+	displayed := &Notification{ID: n.ID, Actions: append([]Action(nil), n.Actions...)}
+	_, launch := toastLaunch(n)
+	go func() {
+		defer func() {
+			_ = cmd.Wait()
+			_listenersMu.Lock()
+			if _listeners[displayed.ID] == cmd {
+				delete(_listeners, displayed.ID)
+			}
+			_listenersMu.Unlock()
+		}()
+		// the values of the inputs are written before the activation, on "input <id> <value>" lines
+		var inputs map[string]string
+		for lines.Scan() {
+			arguments := lines.Text()
+			if fields := strings.SplitN(arguments, " ", 3); len(fields) == 3 && fields[0] == "input" {
+				id, err1 := base64.StdEncoding.DecodeString(fields[1])
+				value, err2 := base64.StdEncoding.DecodeString(fields[2])
+				if err1 == nil && err2 == nil {
+					if inputs == nil {
+						inputs = make(map[string]string)
+					}
+					inputs[string(id)] = string(value)
+				}
+				continue
+			}
+			if !strings.HasPrefix(arguments, "activated ") {
+				continue
+			}
+			// base64 encoded, the arguments may span several lines
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(arguments, "activated "))
+			if err != nil {
+				continue
+			}
+			arguments = string(decoded)
+			// clicking the toast itself activates it with its launch arguments
+			if arguments == launch {
+				arguments = DefaultAction
+			}
+			displayed.DispatchAction(ActionEvent{NotificationID: displayed.ID, ActionID: arguments, Inputs: inputs})
+			inputs = nil
+		}
+	}()
+	return nil
+}
@@ -2,6 +2,7 @@ package toast
 
 import (
 	"testing"
+	"time"
 )
 
 func TestPush(t *testing.T) {
@@ -13,8 +14,26 @@ func TestPush(t *testing.T) {
 	checkErr(t, Push("test_message", WithProtocolAction("Open Maps", "bingmaps:?q=beijing")))
 }
 
-func checkErr(t *testing.T, err error) {
-	if err != nil {
-		t.Fatal(err)
+func TestPush_winrt(t *testing.T) {
+	checkErr(t, Use("winrt"))
+	defer func() {
+		checkErr(t, Use(DefaultBackend))
+	}()
+	h, err := Send("test_message", WithTitle("test_title"), WithTimeout(time.Minute))
+	checkErr(t, err)
+	checkErr(t, h.Close())
+}
+
+func TestPush_worker(t *testing.T) {
+	checkErr(t, Use("powershell-worker"))
+	defer func() {
+		checkErr(t, Use(DefaultBackend))
+	}()
+	for i := 0; i < 3; i++ {
+		checkErr(t, Push("test_message", WithTitle("test_title")))
 	}
+	h, err := Send("test_message", WithProgress("test_title", 0.1, "", "test_status"))
+	checkErr(t, err)
+	checkErr(t, h.SetProgress(0.5))
+	checkErr(t, h.Close())
 }
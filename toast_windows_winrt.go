@@ -0,0 +1,335 @@
+//go:build windows
+
+package toast
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+)
+
+func init() {
+	Register("winrt", func() (Notifier, error) { return winrt{}, nil })
+}
+
+// winrt displays the toasts by calling the Windows Runtime through COM, without spawning PowerShell.
+// It loads the same XML as the powershell backend, which it falls back to for the callbacks and the progress bars:
+// they need events and NotificationData.
+type winrt struct {
+	powershell
+}
+
+func (w winrt) Notify(ctx context.Context, n *Notification) error {
+	if n.hasCallbacks() || n.Progress != nil {
+		return w.powershell.Notify(ctx, n)
+	}
+	if err := validateScenario(n); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(n.ID) == 0 {
+		n.ID = toastTag()
+	}
+	content, err := toastXML(n).Marshal()
+	if err != nil {
+		return err
+	}
+	return withWinRT(func() error { return showToast(n, string(content)) })
+}
+
+// Close removes the toast from the Action Center.
+func (winrt) Close(_ context.Context, n *Notification) error {
+	if len(n.ID) == 0 {
+		return nil
+	}
+	return withWinRT(func() error { return removeToast(n.ID, appID(n)) })
+}
+
+// showToast is the ToastNotificationManager part of the powershell script.
+func showToast(n *Notification, content string) error {
+	doc, err := activateInstance("Windows.Data.Xml.Dom.XmlDocument")
+	if err != nil {
+		return err
+	}
+	defer doc.release()
+	docIO, err := doc.queryInterface(&iidIXmlDocumentIO)
+	if err != nil {
+		return err
+	}
+	defer docIO.release()
+	xmlDoc, err := doc.queryInterface(&iidIXmlDocument)
+	if err != nil {
+		return err
+	}
+	defer xmlDoc.release()
+	hContent, err := newHString(content)
+	if err != nil {
+		return err
+	}
+	defer deleteHString(hContent)
+	if err = docIO.call("LoadXml", 6, uintptr(hContent)); err != nil {
+		return err
+	}
+
+	factory, err := activationFactory("Windows.UI.Notifications.ToastNotification", &iidIToastNotificationFactory)
+	if err != nil {
+		return err
+	}
+	defer factory.release()
+	toast, err := factory.get("CreateToastNotification", 6, uintptr(unsafe.Pointer(xmlDoc)))
+	if err != nil {
+		return err
+	}
+	defer toast.release()
+	if err = setTagAndGroup(toast, n.ID); err != nil {
+		return err
+	}
+	if n.Timeout > 0 {
+		if err = setExpirationTime(toast, time.Now().Add(n.Timeout)); err != nil {
+			return err
+		}
+	}
+
+	manager, err := activationFactory("Windows.UI.Notifications.ToastNotificationManager", &iidIToastNotificationManagerStatics)
+	if err != nil {
+		return err
+	}
+	defer manager.release()
+	hAppID, err := newHString(appID(n))
+	if err != nil {
+		return err
+	}
+	defer deleteHString(hAppID)
+	notifier, err := manager.get("CreateToastNotifierWithId", 7, uintptr(hAppID))
+	if err != nil {
+		return err
+	}
+	defer notifier.release()
+	return notifier.call("Show", 6, uintptr(unsafe.Pointer(toast)))
+}
+
+func setTagAndGroup(toast *comObject, tag string) error {
+	toast2, err := toast.queryInterface(&iidIToastNotification2)
+	if err != nil {
+		return err
+	}
+	defer toast2.release()
+	hTag, err := newHString(tag)
+	if err != nil {
+		return err
+	}
+	defer deleteHString(hTag)
+	hGroup, err := newHString(toastGroup)
+	if err != nil {
+		return err
+	}
+	defer deleteHString(hGroup)
+	if err = toast2.call("put_Tag", 6, uintptr(hTag)); err != nil {
+		return err
+	}
+	return toast2.call("put_Group", 8, uintptr(hGroup))
+}
+
+// setExpirationTime sets the ExpirationTime of the toast, an IReference<DateTime> boxed by PropertyValue.
+func setExpirationTime(toast *comObject, t time.Time) error {
+	statics, err := activationFactory("Windows.Foundation.PropertyValue", &iidIPropertyValueStatics)
+	if err != nil {
+		return err
+	}
+	defer statics.release()
+	// a DateTime counts 100ns intervals since 1601
+	dateTime := t.UnixNano()/100 + 116444736000000000
+	var value *comObject
+	if unsafe.Sizeof(uintptr(0)) == 8 {
+		value, err = statics.get("CreateDateTime", 21, uintptr(dateTime))
+	} else {
+		value, err = statics.get("CreateDateTime", 21, uintptr(uint32(dateTime)), uintptr(uint32(dateTime>>32)))
+	}
+	if err != nil {
+		return err
+	}
+	defer value.release()
+	ref, err := value.queryInterface(&iidIReferenceDateTime)
+	if err != nil {
+		return err
+	}
+	defer ref.release()
+	return toast.call("put_ExpirationTime", 7, uintptr(unsafe.Pointer(ref)))
+}
+
+// removeToast is ToastNotificationManager.History.Remove(tag, group, appID).
+func removeToast(tag, appID string) error {
+	manager, err := activationFactory("Windows.UI.Notifications.ToastNotificationManager", &iidIToastNotificationManagerStatics2)
+	if err != nil {
+		return err
+	}
+	defer manager.release()
+	history, err := manager.get("get_History", 6)
+	if err != nil {
+		return err
+	}
+	defer history.release()
+	var handles [3]hstring
+	for i, s := range []string{tag, toastGroup, appID} {
+		if handles[i], err = newHString(s); err != nil {
+			return err
+		}
+		defer deleteHString(handles[i])
+	}
+	return history.call("RemoveGroupedTagWithId", 8, uintptr(handles[0]), uintptr(handles[1]), uintptr(handles[2]))
+}
+
+var (
+	_combase                   = syscall.NewLazyDLL("combase.dll")
+	procRoInitialize           = _combase.NewProc("RoInitialize")
+	procRoUninitialize         = _combase.NewProc("RoUninitialize")
+	procRoActivateInstance     = _combase.NewProc("RoActivateInstance")
+	procRoGetActivationFactory = _combase.NewProc("RoGetActivationFactory")
+	procWindowsCreateString    = _combase.NewProc("WindowsCreateString")
+	procWindowsDeleteString    = _combase.NewProc("WindowsDeleteString")
+
+	_winrtProcs = []*syscall.LazyProc{
+		procRoInitialize, procRoUninitialize, procRoActivateInstance, procRoGetActivationFactory,
+		procWindowsCreateString, procWindowsDeleteString,
+	}
+)
+
+// the interfaces of windows.data.xml.dom.idl, windows.ui.notifications.idl and windows.foundation.idl
+var (
+	iidIXmlDocument                      = guid{0xf7f3a506, 0x1e87, 0x42d6, [8]byte{0xbc, 0xfb, 0xb8, 0xc8, 0x09, 0xfa, 0x54, 0x94}}
+	iidIXmlDocumentIO                    = guid{0x6cd0e74e, 0xee65, 0x4489, [8]byte{0x9e, 0xbf, 0xca, 0x43, 0xe8, 0x7b, 0xa6, 0x37}}
+	iidIToastNotification2               = guid{0x9dfb9fd1, 0x143a, 0x490e, [8]byte{0x90, 0xbf, 0xb9, 0xfb, 0xa7, 0x13, 0x2d, 0xe7}}
+	iidIToastNotificationFactory         = guid{0x04124b20, 0x82c6, 0x4229, [8]byte{0xb1, 0x09, 0xfd, 0x9e, 0xd4, 0x66, 0x2b, 0x53}}
+	iidIToastNotificationManagerStatics  = guid{0x50ac103f, 0xd235, 0x4598, [8]byte{0xbb, 0xef, 0x98, 0xfe, 0x4d, 0x1a, 0x3a, 0xd4}}
+	iidIToastNotificationManagerStatics2 = guid{0x7ab93c52, 0x0e48, 0x4750, [8]byte{0xba, 0x9d, 0x1a, 0x41, 0x13, 0x98, 0x18, 0x47}}
+	iidIPropertyValueStatics             = guid{0x629bdbc8, 0xd932, 0x4ff4, [8]byte{0x96, 0xb9, 0x8d, 0x96, 0xc5, 0xc1, 0xe8, 0x58}}
+	iidIReferenceDateTime                = guid{0x5541d8a7, 0x497c, 0x5aa4, [8]byte{0x86, 0xfc, 0x77, 0x13, 0xad, 0xbf, 0x2a, 0x2c}}
+)
+
+const (
+	roInitMultithreaded = 1
+	rpcEChangedMode     = 0x80010106
+)
+
+// withWinRT calls fn on a thread initialized for the Windows Runtime.
+func withWinRT(fn func() error) error {
+	for _, proc := range _winrtProcs {
+		if err := proc.Find(); err != nil {
+			return err
+		}
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	hr, _, _ := procRoInitialize.Call(roInitMultithreaded)
+	if uint32(hr) != rpcEChangedMode {
+		// already initialized otherwise, as a single-threaded apartment
+		if err := hresult("RoInitialize", hr); err != nil {
+			return err
+		}
+		defer procRoUninitialize.Call()
+	}
+	return fn()
+}
+
+type guid struct {
+	Data1 uint32
+	Data2 uint16
+	Data3 uint16
+	Data4 [8]byte
+}
+
+// hstring is a Windows Runtime string handle, zero is the empty string.
+type hstring uintptr
+
+func newHString(s string) (hstring, error) {
+	u := utf16.Encode([]rune(s))
+	if len(u) == 0 {
+		return 0, nil
+	}
+	var h hstring
+	hr, _, _ := procWindowsCreateString.Call(uintptr(unsafe.Pointer(&u[0])), uintptr(len(u)), uintptr(unsafe.Pointer(&h)))
+	return h, hresult("WindowsCreateString", hr)
+}
+
+func deleteHString(h hstring) {
+	if h != 0 {
+		_, _, _ = procWindowsDeleteString.Call(uintptr(h))
+	}
+}
+
+func activateInstance(class string) (*comObject, error) {
+	hClass, err := newHString(class)
+	if err != nil {
+		return nil, err
+	}
+	defer deleteHString(hClass)
+	var instance *comObject
+	hr, _, _ := procRoActivateInstance.Call(uintptr(hClass), uintptr(unsafe.Pointer(&instance)))
+	return instance, hresult("RoActivateInstance "+class, hr)
+}
+
+func activationFactory(class string, iid *guid) (*comObject, error) {
+	hClass, err := newHString(class)
+	if err != nil {
+		return nil, err
+	}
+	defer deleteHString(hClass)
+	var factory *comObject
+	hr, _, _ := procRoGetActivationFactory.Call(uintptr(hClass), uintptr(unsafe.Pointer(iid)), uintptr(unsafe.Pointer(&factory)))
+	return factory, hresult("RoGetActivationFactory "+class, hr)
+}
+
+// comObject is a COM interface pointer: the first word of the object points to the table of its methods,
+// QueryInterface, AddRef and Release first, then the IInspectable ones and the methods of the interface from 6 on.
+type comObject struct {
+	vtbl *[32]uintptr
+}
+
+func (o *comObject) queryInterface(iid *guid) (*comObject, error) {
+	var out *comObject
+	hr, _, _ := syscall.Syscall(o.vtbl[0], 3, uintptr(unsafe.Pointer(o)), uintptr(unsafe.Pointer(iid)), uintptr(unsafe.Pointer(&out)))
+	return out, hresult("QueryInterface", hr)
+}
+
+func (o *comObject) release() {
+	_, _, _ = syscall.Syscall(o.vtbl[2], 1, uintptr(unsafe.Pointer(o)), 0, 0)
+}
+
+// call calls the method at index i of the table, args are handles and interface pointers.
+func (o *comObject) call(method string, i int, args ...uintptr) error {
+	var a [3]uintptr
+	copy(a[:], args)
+	hr, _, _ := syscall.Syscall6(o.vtbl[i], uintptr(1+len(args)), uintptr(unsafe.Pointer(o)), a[0], a[1], a[2], 0, 0)
+	return hresult(method, hr)
+}
+
+// get is like call for the methods returning an interface pointer, after at most 2 args.
+func (o *comObject) get(method string, i int, args ...uintptr) (*comObject, error) {
+	var a [2]uintptr
+	copy(a[:], args)
+	var out *comObject
+	var hr uintptr
+	switch len(args) {
+	case 0:
+		hr, _, _ = syscall.Syscall(o.vtbl[i], 2, uintptr(unsafe.Pointer(o)), uintptr(unsafe.Pointer(&out)), 0)
+	case 1:
+		hr, _, _ = syscall.Syscall(o.vtbl[i], 3, uintptr(unsafe.Pointer(o)), a[0], uintptr(unsafe.Pointer(&out)))
+	default:
+		hr, _, _ = syscall.Syscall6(o.vtbl[i], 4, uintptr(unsafe.Pointer(o)), a[0], a[1], uintptr(unsafe.Pointer(&out)), 0, 0)
+	}
+	return out, hresult(method, hr)
+}
+
+// hresult returns an error for a failure HRESULT, nil for S_OK and the other successes.
+func hresult(op string, hr uintptr) error {
+	if int32(hr) >= 0 {
+		return nil
+	}
+	return fmt.Errorf("WinRT: %s: HRESULT 0x%08X", op, uint32(hr))
+}
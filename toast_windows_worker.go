@@ -0,0 +1,157 @@
+//go:build windows
+
+package toast
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"os/exec"
+	"sync"
+	"syscall"
+	"unicode/utf16"
+)
+
+func init() {
+	Register("powershell-worker", func() (Notifier, error) { return powershellWorker{}, nil })
+}
+
+// powershellWorker hands the toasts to a PowerShell process started once and kept running,
+// instead of starting one per toast. It falls back to the powershell backend for the callbacks,
+// which need a process listening to the events of each toast.
+type powershellWorker struct {
+	powershell
+}
+
+var (
+	_worker   *worker
+	_workerMu sync.Mutex
+)
+
+// sharedWorker returns the running worker, started again if it exited.
+func sharedWorker() (*worker, error) {
+	_workerMu.Lock()
+	defer _workerMu.Unlock()
+	if _worker != nil && !_worker.exited() {
+		return _worker, nil
+	}
+	encoded := utf16.Encode([]rune(workerScript))
+	script := make([]byte, 2*len(encoded))
+	for i, u := range encoded {
+		binary.LittleEndian.PutUint16(script[2*i:], u)
+	}
+	// the worker exits once its stdin is closed, at the latest with the process
+	cmd := exec.Command("PowerShell", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass",
+		"-EncodedCommand", base64.StdEncoding.EncodeToString(script))
+	fixCmd("PowerShell", cmd)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	w, err := startWorker(cmd)
+	if err != nil {
+		return nil, err
+	}
+	_worker = w
+	return w, nil
+}
+
+func (p powershellWorker) Notify(ctx context.Context, n *Notification) error {
+	if n.hasCallbacks() {
+		return p.powershell.Notify(ctx, n)
+	}
+	if err := validateScenario(n); err != nil {
+		return err
+	}
+	if len(n.ID) == 0 {
+		n.ID = toastTag()
+	}
+	content, err := toastXML(n).Marshal()
+	if err != nil {
+		return err
+	}
+	req := workerRequest{Op: "show", AppID: appID(n), XML: string(content), Tag: n.ID, Group: toastGroup}
+	if n.Progress != nil {
+		req.Data = progressData(n.Progress)
+	}
+	if n.Timeout > 0 {
+		req.Expiration = n.Timeout.Milliseconds()
+	}
+	return doWorker(ctx, req)
+}
+
+func (powershellWorker) updateProgress(ctx context.Context, n *Notification) error {
+	return doWorker(ctx, workerRequest{Op: "update", AppID: appID(n), Tag: n.ID, Group: toastGroup, Data: progressData(n.Progress)})
+}
+
+// Close removes the toast from the Action Center.
+func (powershellWorker) Close(ctx context.Context, n *Notification) error {
+	if len(n.ID) == 0 {
+		return nil
+	}
+	return doWorker(ctx, workerRequest{Op: "remove", AppID: appID(n), Tag: n.ID, Group: toastGroup})
+}
+
+func doWorker(ctx context.Context, req workerRequest) error {
+	w, err := sharedWorker()
+	if err != nil {
+		return err
+	}
+	return w.do(ctx, req)
+}
+
+// workerScript reads the requests on its stdin and does them as the powershell script would.
+const workerScript = `
+$ErrorActionPreference = 'Stop'
+[Console]::InputEncoding = New-Object Text.UTF8Encoding $false
+[Console]::OutputEncoding = New-Object Text.UTF8Encoding $false
+
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.UI.Notifications.ToastNotification, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+[Windows.UI.Notifications.NotificationData, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+
+function New-GoData($values, $sequenceNumber) {
+    $data = New-Object Windows.UI.Notifications.NotificationData
+    foreach ($value in $values.PSObject.Properties) {
+        $data.Values[$value.Name] = [string]$value.Value
+    }
+    $data.SequenceNumber = $sequenceNumber
+    return $data
+}
+
+while ($null -ne ($line = [Console]::In.ReadLine())) {
+    $response = @{}
+    try {
+        $request = ConvertFrom-Json $line
+        $response.id = $request.id
+        $notifier = [Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($request.app_id)
+        switch ($request.op) {
+            'show' {
+                $xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+                $xml.LoadXml($request.xml)
+                $go_toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+                $go_toast.Tag = $request.tag
+                $go_toast.Group = $request.group
+                if ($request.data) {
+                    $go_toast.Data = New-GoData $request.data 1
+                }
+                if ($request.expiration) {
+                    $go_toast.ExpirationTime = [DateTimeOffset]::Now.AddMilliseconds($request.expiration)
+                }
+                $notifier.Show($go_toast)
+            }
+            'update' {
+                # zero updates whatever the sequence number of the toast
+                $notifier.Update((New-GoData $request.data 0), $request.tag, $request.group) | Out-Null
+            }
+            'remove' {
+                [Windows.UI.Notifications.ToastNotificationManager]::History.Remove($request.tag, $request.group, $request.app_id)
+            }
+            default {
+                throw "unknown op $($request.op)"
+            }
+        }
+    } catch {
+        $response.error = $_.Exception.Message
+    }
+    [Console]::Out.WriteLine((ConvertTo-Json $response -Compress))
+}
+`
@@ -0,0 +1,233 @@
+// Package toasttest provides a backend recording the notifications instead of displaying them,
+// for the tests of the applications using toast.
+//
+//	func TestBuild(t *testing.T) {
+//		rec := toasttest.Use(t)
+//		build()
+//		rec.AssertPushed(t, "build", "finished")
+//	}
+package toasttest
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/electricbubble/go-toast"
+)
+
+// Name is the name of the backend selected by Use.
+const Name = "toasttest"
+
+var (
+	_mu      sync.Mutex
+	_current *Recorder
+)
+
+func init() {
+	toast.Register(Name, func() (toast.Notifier, error) {
+		_mu.Lock()
+		defer _mu.Unlock()
+		if _current == nil {
+			return NewRecorder(), nil
+		}
+		return _current, nil
+	})
+}
+
+// Use selects a new Recorder as the backend of toast.Push until the end of the test,
+// the default backend is selected again afterwards.
+// Tests using it must not run in parallel.
+func Use(t testing.TB) *Recorder {
+	t.Helper()
+	r := NewRecorder()
+	_mu.Lock()
+	_current = r
+	_mu.Unlock()
+	if err := toast.Use(Name); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_mu.Lock()
+		_current = nil
+		_mu.Unlock()
+		if err := toast.Use(toast.DefaultBackend); err != nil {
+			t.Error(err)
+		}
+	})
+	return r
+}
+
+// Record is a notification handed to a Recorder.
+type Record struct {
+	// The notification as the backend got it, once the options and the defaults were applied
+	Notification toast.Notification
+
+	// When the notification was recorded
+	Time time.Time
+
+	// Whether the notification was closed, by its Handle or by Dismiss
+	Closed bool
+
+	// the notification the callbacks of which are called by Click
+	n *toast.Notification
+}
+
+// Recorder is a toast.Notifier recording the notifications instead of displaying them,
+// assigning them the ids "1", "2", ... like a freedesktop server.
+type Recorder struct {
+	mu       sync.Mutex
+	records  []Record
+	failures []error
+	lastID   int
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// FailNext has the next calls to Notify fail with errs, one error per call.
+func (r *Recorder) FailNext(errs ...error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = append(r.failures, errs...)
+}
+
+func (r *Recorder) Notify(ctx context.Context, n *toast.Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.failures) != 0 {
+		err := r.failures[0]
+		r.failures = r.failures[1:]
+		return err
+	}
+	if len(n.ID) == 0 {
+		r.lastID++
+		n.ID = strconv.Itoa(r.lastID)
+	}
+	r.records = append(r.records, Record{Notification: *n, Time: time.Now(), n: n})
+	return nil
+}
+
+// WritesIcon is true: the icon given toast.WithIconRaw and the like is kept in memory, not written to a file.
+// Every option is honored, a Recorder reports none as dropped.
+func (*Recorder) WritesIcon() bool { return true }
+
+// Close marks the records of the notification as closed.
+func (r *Recorder) Close(_ context.Context, n *toast.Notification) error {
+	r.Dismiss(n.ID)
+	return nil
+}
+
+// Records returns the recorded notifications, in the order they were displayed.
+// An update is recorded once more with the same id.
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Record(nil), r.records...)
+}
+
+// Last returns the last recorded notification, false if there's none.
+func (r *Recorder) Last() (Record, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.records) == 0 {
+		return Record{}, false
+	}
+	return r.records[len(r.records)-1], true
+}
+
+// Reset forgets the recorded notifications and the scripted failures.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+	r.failures = nil
+}
+
+// Click simulates the user clicking the action of the notification with the id,
+// toast.DefaultAction for the notification itself.
+// It reports whether a callback was called, there's none once the notification is closed.
+func (r *Recorder) Click(id, actionID string) bool {
+	return r.Submit(id, actionID, nil)
+}
+
+// Submit is like Click, with the values of the inputs of the notification by input id.
+func (r *Recorder) Submit(id, actionID string, inputs map[string]string) bool {
+	r.mu.Lock()
+	var n *toast.Notification
+	for _, rec := range r.records {
+		if rec.Notification.ID == id {
+			n = rec.n
+			if rec.Closed {
+				n = nil
+			}
+		}
+	}
+	r.mu.Unlock()
+	if n == nil {
+		return false
+	}
+	return n.DispatchAction(toast.ActionEvent{NotificationID: id, ActionID: actionID, Inputs: inputs})
+}
+
+// Dismiss simulates the user closing the notification with the id.
+func (r *Recorder) Dismiss(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.records {
+		if r.records[i].Notification.ID == id {
+			r.records[i].Closed = true
+		}
+	}
+}
+
+// AssertCount fails the test if the count of recorded notifications isn't expected.
+func (r *Recorder) AssertCount(t testing.TB, expected int) {
+	t.Helper()
+	if records := r.Records(); len(records) != expected {
+		t.Errorf("toasttest: expected %d notifications, got %d: %s", expected, len(records), summary(records))
+	}
+}
+
+// AssertPushed fails the test if no notification with the title and message was recorded.
+func (r *Recorder) AssertPushed(t testing.TB, title, message string) {
+	t.Helper()
+	records := r.Records()
+	for _, rec := range records {
+		if rec.Notification.Title == title && rec.Notification.Message == message {
+			return
+		}
+	}
+	t.Errorf("toasttest: expected a notification %q: %q, got %s", title, message, summary(records))
+}
+
+// AssertClosed fails the test if the notification with the id wasn't closed.
+func (r *Recorder) AssertClosed(t testing.TB, id string) {
+	t.Helper()
+	for _, rec := range r.Records() {
+		if rec.Notification.ID == id && rec.Closed {
+			return
+		}
+	}
+	t.Errorf("toasttest: expected notification %q to be closed", id)
+}
+
+func summary(records []Record) string {
+	if len(records) == 0 {
+		return "none"
+	}
+	s := ""
+	for i, rec := range records {
+		if i != 0 {
+			s += ", "
+		}
+		s += strconv.Quote(rec.Notification.Title) + ": " + strconv.Quote(rec.Notification.Message)
+	}
+	return s
+}
@@ -0,0 +1,103 @@
+package toasttest_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/electricbubble/go-toast"
+	"github.com/electricbubble/go-toast/toasttest"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := toasttest.Use(t)
+
+	var clicked []toast.ActionEvent
+	h, err := toast.Send("test_message",
+		toast.WithTitle("test_title"),
+		toast.WithAction("open", "Open", func(ev toast.ActionEvent) { clicked = append(clicked, ev) }),
+	)
+	checkErr(t, err)
+	if h.ID() != "1" {
+		t.Errorf("expected id 1, got %q", h.ID())
+	}
+	checkErr(t, h.Update(toast.WithMessage("test_update")))
+	rec.AssertCount(t, 2)
+	rec.AssertPushed(t, "test_title", "test_message")
+	rec.AssertPushed(t, "test_title", "test_update")
+
+	last, ok := rec.Last()
+	if !ok || last.Notification.ID != "1" || len(last.Notification.Actions) != 1 || last.Time.IsZero() {
+		t.Errorf("unexpected last record: %+v", last)
+	}
+
+	if !rec.Click("1", "open") || rec.Click("1", "unknown") || rec.Click("2", "open") {
+		t.Error("expected only the open action of notification 1 to have a callback")
+	}
+	if len(clicked) != 1 || clicked[0].ActionID != "open" || clicked[0].NotificationID != "1" {
+		t.Errorf("unexpected clicks: %+v", clicked)
+	}
+
+	if !rec.Submit("1", "open", map[string]string{"reply": "test_reply"}) || clicked[1].Inputs["reply"] != "test_reply" {
+		t.Errorf("expected the inputs to be passed to the callback, got %+v", clicked)
+	}
+
+	checkErr(t, h.Close())
+	rec.AssertClosed(t, "1")
+	if rec.Click("1", "open") {
+		t.Error("expected no callback once the notification is closed")
+	}
+
+	errFailing := errors.New("failing")
+	rec.FailNext(errFailing)
+	if err = toast.Push("test_message"); !errors.Is(err, errFailing) {
+		t.Errorf("expected %v, got %v", errFailing, err)
+	}
+	checkErr(t, toast.Push("test_message"))
+	rec.AssertCount(t, 3)
+
+	rec.Reset()
+	rec.AssertCount(t, 0)
+}
+
+func TestRecorder_icon(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	t.Setenv("HOME", cache)
+	rec := toasttest.Use(t)
+
+	checkErr(t, toast.Push("test_message", toast.WithIconRaw([]byte("not an image")), toast.WithStrict()))
+	rec.AssertCount(t, 1)
+	if last, _ := rec.Last(); len(last.Notification.Icon) != 0 {
+		t.Errorf("expected the icon to be kept in memory, got %q", last.Notification.Icon)
+	}
+	if entries, err := os.ReadDir(cache); err != nil || len(entries) != 0 {
+		t.Errorf("expected no icon file, got %v (%v)", entries, err)
+	}
+}
+
+func TestRecorder_assertions(t *testing.T) {
+	rec := toasttest.NewRecorder()
+	mock := &mockT{TB: t}
+	rec.AssertCount(mock, 1)
+	rec.AssertPushed(mock, "test_title", "test_message")
+	rec.AssertClosed(mock, "1")
+	if mock.errors != 3 {
+		t.Errorf("expected 3 failed assertions, got %d", mock.errors)
+	}
+}
+
+type mockT struct {
+	testing.TB
+	errors int
+}
+
+func (m *mockT) Errorf(string, ...interface{}) {
+	m.errors++
+}
+
+func checkErr(t *testing.T, err error) {
+	if err != nil {
+		t.Fatal(err)
+	}
+}
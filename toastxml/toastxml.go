@@ -0,0 +1,205 @@
+// Package toastxml models the XML content of Windows toast notifications,
+// so that it is built with encoding/xml instead of text templates.
+//
+// See https://learn.microsoft.com/en-us/uwp/schemas/tiles/toastschema/schema-root
+package toastxml
+
+import (
+	"encoding/xml"
+)
+
+// Toast is the root element of the toast content.
+type Toast struct {
+	XMLName xml.Name `xml:"toast"`
+
+	// The arguments passed to the application when the toast itself is clicked
+	Launch string `xml:"launch,attr,omitempty"`
+
+	// foreground, background or protocol
+	ActivationType string `xml:"activationType,attr,omitempty"`
+
+	// short or long
+	Duration string `xml:"duration,attr,omitempty"`
+
+	// reminder, alarm, incomingCall or urgent
+	Scenario string `xml:"scenario,attr,omitempty"`
+
+	// An ISO 8601 timestamp replacing the time the toast was delivered
+	DisplayTimestamp string `xml:"displayTimestamp,attr,omitempty"`
+
+	UseButtonStyle bool `xml:"useButtonStyle,attr,omitempty"`
+
+	Visual  Visual   `xml:"visual"`
+	Audio   *Audio   `xml:"audio,omitempty"`
+	Actions *Actions `xml:"actions,omitempty"`
+	Header  *Header  `xml:"header,omitempty"`
+}
+
+// Marshal returns the XML encoding of the toast.
+func (t *Toast) Marshal() ([]byte, error) {
+	return xml.Marshal(t)
+}
+
+// Visual holds the binding of the toast.
+type Visual struct {
+	Lang    string `xml:"lang,attr,omitempty"`
+	BaseURI string `xml:"baseUri,attr,omitempty"`
+
+	Binding Binding `xml:"binding"`
+}
+
+// Binding holds the elements displayed by the toast.
+type Binding struct {
+	// ToastGeneric
+	Template string `xml:"template,attr"`
+
+	Lang string `xml:"lang,attr,omitempty"`
+
+	// *Text, *Image, *Group and *Progress, in the order they are displayed
+	Children []Element
+}
+
+// Element is a child of a Binding or a Subgroup.
+type Element interface {
+	element()
+}
+
+// Text is a line of text, the first one is the title of the toast.
+type Text struct {
+	XMLName xml.Name `xml:"text"`
+
+	Lang string `xml:"lang,attr,omitempty"`
+
+	// attribution displays the text below the others, in a smaller font
+	Placement string `xml:"placement,attr,omitempty"`
+
+	// The style of the text within a Subgroup, like caption or base
+	HintStyle    string `xml:"hint-style,attr,omitempty"`
+	HintWrap     bool   `xml:"hint-wrap,attr,omitempty"`
+	HintMaxLines int    `xml:"hint-maxLines,attr,omitempty"`
+	HintMinLines int    `xml:"hint-minLines,attr,omitempty"`
+	HintAlign    string `xml:"hint-align,attr,omitempty"`
+
+	Content string `xml:",chardata"`
+}
+
+func (*Text) element() {}
+
+// Image is displayed inline, unless it has a Placement.
+type Image struct {
+	XMLName xml.Name `xml:"image"`
+
+	// A file:// or ms-appx:/// URI, or an absolute path
+	Src string `xml:"src,attr"`
+	Alt string `xml:"alt,attr,omitempty"`
+
+	// appLogoOverride replaces the logo of the app, hero displays the image above the text
+	Placement string `xml:"placement,attr,omitempty"`
+
+	// circle crops the image
+	HintCrop string `xml:"hint-crop,attr,omitempty"`
+
+	HintRemoveMargin bool   `xml:"hint-removeMargin,attr,omitempty"`
+	HintAlign        string `xml:"hint-align,attr,omitempty"`
+	AddImageQuery    bool   `xml:"addImageQuery,attr,omitempty"`
+}
+
+func (*Image) element() {}
+
+// Group displays its subgroups side by side.
+type Group struct {
+	XMLName xml.Name `xml:"group"`
+
+	Subgroups []Subgroup `xml:"subgroup"`
+}
+
+func (*Group) element() {}
+
+// Subgroup is a column of a Group, holding *Text and *Image elements.
+type Subgroup struct {
+	// The width of the column relative to the other ones
+	HintWeight int `xml:"hint-weight,attr,omitempty"`
+
+	HintTextStacking string `xml:"hint-textStacking,attr,omitempty"`
+
+	Children []Element
+}
+
+// Progress is a progress bar, its attributes may be bound to the data of the toast as {name}.
+type Progress struct {
+	XMLName xml.Name `xml:"progress"`
+
+	Title string `xml:"title,attr,omitempty"`
+
+	// A value between 0.0 and 1.0, or indeterminate
+	Value string `xml:"value,attr"`
+
+	// Replaces the percentage displayed
+	ValueStringOverride string `xml:"valueStringOverride,attr,omitempty"`
+
+	Status string `xml:"status,attr"`
+}
+
+func (*Progress) element() {}
+
+// Audio is played when the toast is displayed.
+type Audio struct {
+	// A ms-winsoundevent: sound
+	Src    string `xml:"src,attr,omitempty"`
+	Loop   bool   `xml:"loop,attr,omitempty"`
+	Silent bool   `xml:"silent,attr,omitempty"`
+}
+
+// Actions holds the inputs and the buttons of the toast, at most 5 of each.
+type Actions struct {
+	Inputs  []Input  `xml:"input"`
+	Actions []Action `xml:"action"`
+}
+
+// Input is a text box or a selection box, its value is passed along with the activation of an action.
+type Input struct {
+	ID string `xml:"id,attr"`
+
+	// text or selection
+	Type string `xml:"type,attr"`
+
+	Title              string `xml:"title,attr,omitempty"`
+	PlaceHolderContent string `xml:"placeHolderContent,attr,omitempty"`
+	DefaultInput       string `xml:"defaultInput,attr,omitempty"`
+
+	Selections []Selection `xml:"selection"`
+}
+
+// Selection is a choice of a selection Input.
+type Selection struct {
+	ID      string `xml:"id,attr"`
+	Content string `xml:"content,attr"`
+}
+
+// Action is a button.
+type Action struct {
+	Content   string `xml:"content,attr"`
+	Arguments string `xml:"arguments,attr"`
+
+	// foreground, background or protocol
+	ActivationType string `xml:"activationType,attr,omitempty"`
+
+	// contextMenu moves the action to the context menu of the toast
+	Placement string `xml:"placement,attr,omitempty"`
+
+	ImageURI string `xml:"imageUri,attr,omitempty"`
+
+	// Displays the button next to the Input with this id
+	HintInputID string `xml:"hint-inputId,attr,omitempty"`
+
+	// Success or Critical, with UseButtonStyle
+	HintButtonStyle string `xml:"hint-buttonStyle,attr,omitempty"`
+}
+
+// Header groups the toasts with the same ID in the Action Center.
+type Header struct {
+	ID             string `xml:"id,attr"`
+	Title          string `xml:"title,attr"`
+	Arguments      string `xml:"arguments,attr"`
+	ActivationType string `xml:"activationType,attr,omitempty"`
+}
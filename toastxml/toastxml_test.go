@@ -0,0 +1,59 @@
+package toastxml
+
+import (
+	"testing"
+)
+
+func TestToast_Marshal(t *testing.T) {
+	toast := &Toast{
+		Launch:   "action=view&id=1",
+		Scenario: "reminder",
+		Visual: Visual{Binding: Binding{
+			Template: "ToastGeneric",
+			Children: []Element{
+				&Text{Content: "<title>"},
+				&Image{Src: "hero.png", Placement: "hero"},
+				&Group{Subgroups: []Subgroup{
+					{HintWeight: 1, Children: []Element{&Text{Content: "left", HintStyle: "caption"}}},
+					{HintWeight: 2, Children: []Element{&Image{Src: "right.png", HintCrop: "circle"}}},
+				}},
+				&Progress{Value: "{progressValue}", Status: "Downloading..."},
+			},
+		}},
+		Audio: &Audio{Silent: true},
+		Actions: &Actions{
+			Inputs: []Input{{ID: "reply", Type: "text", PlaceHolderContent: `"reply"`}},
+			Actions: []Action{{
+				Content:        "Send",
+				Arguments:      "send",
+				ActivationType: "background",
+				HintInputID:    "reply",
+			}},
+		},
+		Header: &Header{ID: "builds", Title: "Builds", Arguments: "builds"},
+	}
+	bs, err := toast.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<toast launch="action=view&amp;id=1" scenario="reminder">` +
+		`<visual><binding template="ToastGeneric">` +
+		`<text>&lt;title&gt;</text>` +
+		`<image src="hero.png" placement="hero"></image>` +
+		`<group>` +
+		`<subgroup hint-weight="1"><text hint-style="caption">left</text></subgroup>` +
+		`<subgroup hint-weight="2"><image src="right.png" hint-crop="circle"></image></subgroup>` +
+		`</group>` +
+		`<progress value="{progressValue}" status="Downloading..."></progress>` +
+		`</binding></visual>` +
+		`<audio silent="true"></audio>` +
+		`<actions>` +
+		`<input id="reply" type="text" placeHolderContent="&#34;reply&#34;"></input>` +
+		`<action content="Send" arguments="send" activationType="background" hint-inputId="reply"></action>` +
+		`</actions>` +
+		`<header id="builds" title="Builds" arguments="builds"></header>` +
+		`</toast>`
+	if string(bs) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, bs)
+	}
+}
@@ -0,0 +1,69 @@
+package toast
+
+// urgencyMapping is how a platform displays an urgency level.
+type urgencyMapping struct {
+	// The freedesktop urgency hint
+	freedesktop byte
+
+	// The Windows toast scenario, "urgent" needs Windows 11 and
+	// "alarm" keeps the toast on screen but is only honored with buttons
+	windowsScenario, windowsScenarioWithButtons string
+
+	// The macOS UNNotificationInterruptionLevel
+	interruptionLevel string
+
+	// The web requireInteraction option
+	requireInteraction bool
+}
+
+var urgencies = map[Urgency]urgencyMapping{
+	Low: {
+		freedesktop:       0,
+		interruptionLevel: "passive",
+	},
+	Normal: {
+		freedesktop:       1,
+		interruptionLevel: "active",
+	},
+	Critical: {
+		freedesktop:                2,
+		windowsScenario:            "urgent",
+		windowsScenarioWithButtons: "alarm",
+		interruptionLevel:          "timeSensitive",
+		requireInteraction:         true,
+	},
+}
+
+// mapping returns how the platforms display u, an unknown urgency is Normal.
+func (u Urgency) mapping() urgencyMapping {
+	if m, ok := urgencies[u]; ok {
+		return m
+	}
+	return urgencies[Normal]
+}
+
+// freedesktop returns the value of the freedesktop urgency hint.
+func (u Urgency) freedesktop() byte {
+	return u.mapping().freedesktop
+}
+
+// windowsScenario returns the scenario attribute of the toast, empty for the default one.
+func (u Urgency) windowsScenario(hasButtons bool) string {
+	if hasButtons {
+		return u.mapping().windowsScenarioWithButtons
+	}
+	return u.mapping().windowsScenario
+}
+
+// interruptionLevel returns the macOS interruption level, empty if unset.
+func (u Urgency) interruptionLevel() string {
+	if len(u) == 0 {
+		return ""
+	}
+	return u.mapping().interruptionLevel
+}
+
+// requireInteraction reports whether the notification stays in the browser until the user acts on it.
+func (u Urgency) requireInteraction() bool {
+	return u.mapping().requireInteraction
+}
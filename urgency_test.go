@@ -0,0 +1,37 @@
+package toast
+
+import (
+	"testing"
+)
+
+func TestUrgency(t *testing.T) {
+	for _, tt := range []struct {
+		urgency                   Urgency
+		freedesktop               byte
+		scenario, scenarioButtons string
+		interruptionLevel         string
+		requireInteraction        bool
+	}{
+		{"", 1, "", "", "", false},
+		{Low, 0, "", "", "passive", false},
+		{Normal, 1, "", "", "active", false},
+		{Critical, 2, "urgent", "alarm", "timeSensitive", true},
+		{"unknown", 1, "", "", "active", false},
+	} {
+		if got := tt.urgency.freedesktop(); got != tt.freedesktop {
+			t.Errorf("%q: expected freedesktop urgency %d, got %d", tt.urgency, tt.freedesktop, got)
+		}
+		if got := tt.urgency.windowsScenario(false); got != tt.scenario {
+			t.Errorf("%q: expected scenario %q, got %q", tt.urgency, tt.scenario, got)
+		}
+		if got := tt.urgency.windowsScenario(true); got != tt.scenarioButtons {
+			t.Errorf("%q: expected scenario %q with buttons, got %q", tt.urgency, tt.scenarioButtons, got)
+		}
+		if got := tt.urgency.interruptionLevel(); got != tt.interruptionLevel {
+			t.Errorf("%q: expected interruption level %q, got %q", tt.urgency, tt.interruptionLevel, got)
+		}
+		if got := tt.urgency.requireInteraction(); got != tt.requireInteraction {
+			t.Errorf("%q: expected requireInteraction %v, got %v", tt.urgency, tt.requireInteraction, got)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package toast
+
+import (
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/electricbubble/go-toast/toastxml"
+)
+
+// toastXML returns the content of the Windows toast displaying n.
+func toastXML(n *Notification) *toastxml.Toast {
+	activationType, launch := toastLaunch(n)
+	t := &toastxml.Toast{
+		Launch:         launch,
+		ActivationType: activationType,
+		Duration:       string(toastDuration(n)),
+		Visual: toastxml.Visual{
+			Binding: toastxml.Binding{Template: "ToastGeneric"},
+		},
+	}
+	if !n.Timestamp.IsZero() {
+		t.DisplayTimestamp = n.Timestamp.Format(time.RFC3339)
+	}
+
+	binding := &t.Visual.Binding
+	if len(n.HeroImage) != 0 {
+		binding.Children = append(binding.Children, &toastxml.Image{Placement: "hero", Src: n.HeroImage})
+	}
+	if len(n.Icon) != 0 {
+		binding.Children = append(binding.Children, &toastxml.Image{
+			Placement: "appLogoOverride",
+			Src:       n.Icon,
+			HintCrop:  string(n.IconCrop),
+		})
+	}
+	for _, text := range []string{n.Title, n.Subtitle, n.Message} {
+		if len(text) != 0 {
+			binding.Children = append(binding.Children, &toastxml.Text{Content: text})
+		}
+	}
+	if len(n.Image) != 0 {
+		binding.Children = append(binding.Children, &toastxml.Image{Src: n.Image})
+	}
+	if n.Progress != nil {
+		progress := &toastxml.Progress{Value: "{progressValue}", Status: "{progressStatus}"}
+		if len(n.Progress.Title) != 0 {
+			progress.Title = "{progressTitle}"
+		}
+		if len(n.Progress.ValueString) != 0 {
+			progress.ValueStringOverride = "{progressValueString}"
+		}
+		binding.Children = append(binding.Children, progress)
+	}
+	if len(n.Attribution) != 0 {
+		binding.Children = append(binding.Children, &toastxml.Text{Placement: "attribution", Content: n.Attribution})
+	}
+
+	if audio, loop := toastAudio(n); n.Silent || audio == Silent {
+		t.Audio = &toastxml.Audio{Silent: true}
+	} else if len(audio) != 0 {
+		t.Audio = &toastxml.Audio{Src: string(audio), Loop: loop}
+	}
+
+	buttons := n.buttons()
+	if len(buttons) != 0 || len(n.Inputs) != 0 {
+		t.Actions = &toastxml.Actions{}
+		textInputs := make(map[string]bool, len(n.Inputs))
+		for _, in := range n.Inputs {
+			input := toastxml.Input{ID: in.ID, Type: in.Type, PlaceHolderContent: in.Placeholder}
+			for _, c := range in.Choices {
+				input.Selections = append(input.Selections, toastxml.Selection{ID: c.ID, Content: c.Label})
+			}
+			t.Actions.Inputs = append(t.Actions.Inputs, input)
+			textInputs[in.ID] = in.Type == "text"
+		}
+		for _, b := range buttons {
+			action := toastxml.Action{
+				Content:        b.Label,
+				Arguments:      b.Arguments,
+				ActivationType: b.Type,
+			}
+			// the send button of a text box
+			if len(b.ID) != 0 && textInputs[b.ID] {
+				action.HintInputID = b.ID
+			}
+			t.Actions.Actions = append(t.Actions.Actions, action)
+		}
+	}
+	t.Scenario = string(n.scenario())
+	return t
+}
+
+// progressData returns the values bound to the progress bar of the toast, by key.
+func progressData(p *Progress) map[string]string {
+	value := "indeterminate"
+	if p.Value >= 0 {
+		value = strconv.FormatFloat(clamp(p.Value), 'f', -1, 64)
+	}
+	return map[string]string{
+		"progressTitle":       p.Title,
+		"progressValue":       value,
+		"progressValueString": p.ValueString,
+		"progressStatus":      p.Status,
+	}
+}
+
+func clamp(v float64) float64 {
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// quotePowerShell returns a PowerShell expression evaluating to s, usable as a command argument too.
+// The string is base64 encoded: PowerShell takes the typographic quotes for quotes as well,
+// and a here-string ends at any line starting with '@ or "@.
+func quotePowerShell(s string) string {
+	return "([Text.Encoding]::UTF8.GetString([Convert]::FromBase64String('" + base64.StdEncoding.EncodeToString([]byte(s)) + "')))"
+}
+
+// toastLaunch returns the activation type and the launch arguments of the toast itself. With callbacks,
+// clicking it has to raise the Activated event, with arguments telling it apart from the buttons.
+func toastLaunch(n *Notification) (activationType, launch string) {
+	activationType, launch = n.ActivationType, n.ActivationArguments
+	// the default of the Windows newNotification, for notifications built otherwise
+	if len(activationType) == 0 {
+		activationType = "protocol"
+	}
+	if !n.hasCallbacks() {
+		return activationType, launch
+	}
+	if !n.uses("WithActivationType") {
+		activationType = "foreground"
+	}
+	if len(launch) == 0 {
+		launch = "go-toast-launch:" + n.ID
+	}
+	return activationType, launch
+}
+
+// longToast is about how long a long toast shows up for, a short one lasts 7s.
+const longToast = 25 * time.Second
+
+// toastDuration returns the duration of the toast closest to the timeout of n, n.Duration without one.
+func toastDuration(n *Notification) NotificationDuration {
+	_, loop := toastAudio(n)
+	switch {
+	case n.Persistent, loop && n.scenario().keepsOnScreen():
+		return Long
+	case n.Timeout > 0 && n.Timeout < longToast/2:
+		return Short
+	case n.Timeout > 0:
+		return Long
+	case len(n.Duration) == 0:
+		return Short
+	default:
+		return n.Duration
+	}
+}
@@ -0,0 +1,99 @@
+//go:build go1.18
+
+package toast
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// FuzzToastXML checks that any text reaches the toast as literal text, it can't add elements or attributes.
+func FuzzToastXML(f *testing.F) {
+	f.Add("title", "message", `C:\icon.png`, "https://example.com/?a=1&b=2", "label")
+	f.Add(`"@`, "]]>\n'@\n\"@", `"/><script/>`, "<![CDATA[", "`$(Remove-Item C:\\)")
+	f.Add("’; Remove-Item C:\\ ; ‘", "\x00\x1b\r\n\t", "\uFFFE", "&amp;", "\xff")
+	f.Fuzz(func(t *testing.T, title, message, icon, arguments, label string) {
+		n := &Notification{
+			Title:               title,
+			Message:             message,
+			Icon:                icon,
+			ActivationArguments: arguments,
+			Actions:             []Action{{Type: "protocol", Label: label, Arguments: arguments}},
+		}
+		bs, err := toastXML(n).Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var parsed struct {
+			Launch string `xml:"launch,attr"`
+			Images []struct {
+				Src string `xml:"src,attr"`
+			} `xml:"visual>binding>image"`
+			Texts   []string `xml:"visual>binding>text"`
+			Actions []struct {
+				Content   string `xml:"content,attr"`
+				Arguments string `xml:"arguments,attr"`
+			} `xml:"actions>action"`
+		}
+		if err = xml.Unmarshal(bs, &parsed); err != nil {
+			t.Fatalf("invalid XML %s: %v", bs, err)
+		}
+		var texts []string
+		for _, s := range []string{title, message} {
+			if len(s) != 0 {
+				texts = append(texts, literal(s))
+			}
+		}
+		if strings.Join(parsed.Texts, "\x00") != strings.Join(texts, "\x00") || len(parsed.Texts) != len(texts) {
+			t.Errorf("expected texts %q, got %q", texts, parsed.Texts)
+		}
+		if parsed.Launch != literal(arguments) {
+			t.Errorf("expected launch %q, got %q", literal(arguments), parsed.Launch)
+		}
+		if len(icon) != 0 && (len(parsed.Images) != 1 || parsed.Images[0].Src != literal(icon)) {
+			t.Errorf("expected image %q, got %+v", literal(icon), parsed.Images)
+		}
+		if len(parsed.Actions) != 1 || parsed.Actions[0].Content != literal(label) || parsed.Actions[0].Arguments != literal(arguments) {
+			t.Errorf("expected action %q %q, got %+v", literal(label), literal(arguments), parsed.Actions)
+		}
+
+		quoted := quotePowerShell(string(bs))
+		m := quotedPowerShell.FindStringSubmatch(quoted)
+		if m == nil {
+			t.Fatalf("unexpected PowerShell expression %s", quoted)
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(m[1]); err != nil || string(decoded) != string(bs) {
+			t.Errorf("expected %s to decode to the XML, got %q, %v", quoted, decoded, err)
+		}
+	})
+}
+
+var quotedPowerShell = regexp.MustCompile(`^\(\[Text\.Encoding\]::UTF8\.GetString\(\[Convert\]::FromBase64String\('([A-Za-z0-9+/=]*)'\)\)\)$`)
+
+// literal returns s as XML is able to carry it: invalid UTF-8 and the characters XML 1.0 excludes
+// are replaced by U+FFFD.
+func literal(s string) string {
+	var b strings.Builder
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		if !isXMLChar(r) || r == utf8.RuneError && size == 1 {
+			r = utf8.RuneError
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// https://www.w3.org/TR/xml/#charsets
+func isXMLChar(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
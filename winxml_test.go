@@ -0,0 +1,142 @@
+package toast
+
+import (
+	"encoding/xml"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestToastXML(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		n    *Notification
+	}{
+		{"minimal", &Notification{Title: "test_title", Message: "test_message"}},
+		{"defaults", &Notification{
+			Title:          "GO APP",
+			Message:        "test_message",
+			Audio:          Silent,
+			AppID:          "GO APP",
+			ActivationType: "protocol",
+			Duration:       Short,
+		}},
+		{"full", &Notification{
+			Title:               `<test title="&">`,
+			Subtitle:            "test_subtitle",
+			Message:             "line 1\nline 2 ]]> '@",
+			Audio:               LoopingAlarm,
+			Loop:                true,
+			Icon:                `C:\icon.png`,
+			IconCrop:            Circle,
+			Image:               `C:\image.png`,
+			HeroImage:           `C:\hero.png`,
+			Attribution:         "Via SMS",
+			ActivationArguments: "https://example.com/?a=1&b=2",
+			Actions: []Action{
+				{Type: "protocol", Label: "Open Maps", Arguments: "bingmaps:?q=sushi"},
+				{Type: "background", Label: "Snooze", Arguments: "snooze", ID: "snooze"},
+			},
+			Urgency:   Critical,
+			Timeout:   time.Minute,
+			Timestamp: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+		}},
+		{"inputs", &Notification{
+			Title:    "test_title",
+			Scenario: ScenarioReminder,
+			Inputs: []Input{
+				{ID: "reply", Type: "text", Placeholder: "Type a reply"},
+				{ID: "time", Type: "selection", Choices: []Choice{{ID: "15", Label: "15 minutes"}, {ID: "60", Label: "1 hour"}}},
+			},
+			Actions: []Action{
+				{Type: "background", Label: "Send", Arguments: "reply", ID: "reply"},
+				{Type: "background", Label: "Snooze", Arguments: "snooze", ID: "snooze"},
+			},
+		}},
+		{"progress", &Notification{
+			Title:    "test_title",
+			Progress: &Progress{Title: "video.mp4", Value: 0.6, ValueString: "3/5 files", Status: "Downloading..."},
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			bs, err := xml.MarshalIndent(toastXML(tt.n), "", "  ")
+			checkErr(t, err)
+			golden(t, filepath.Join("testdata", "toast_"+tt.name+".xml"), append(bs, '\n'))
+		})
+	}
+}
+
+func TestToastDuration(t *testing.T) {
+	for _, tt := range []struct {
+		n        Notification
+		expected NotificationDuration
+	}{
+		{Notification{}, Short},
+		{Notification{Duration: Long}, Long},
+		{Notification{Duration: Long, Timeout: 5 * time.Second}, Short},
+		{Notification{Timeout: 20 * time.Second}, Long},
+		{Notification{Persistent: true}, Long},
+	} {
+		if got := toastDuration(&tt.n); got != tt.expected {
+			t.Errorf("%+v: expected %q, got %q", tt.n, tt.expected, got)
+		}
+	}
+}
+
+func TestToastLaunch(t *testing.T) {
+	nop := func(ActionEvent) {}
+	for _, tt := range []struct {
+		name                   string
+		opts                   []NotificationOption
+		activationType, launch string
+	}{
+		{"no callbacks", []NotificationOption{WithAction("snooze", "Snooze", nil)}, "protocol", ""},
+		{"default action", []NotificationOption{WithAction(DefaultAction, "", nop)}, "foreground", "go-toast-launch:test_id"},
+		{"button", []NotificationOption{WithAction("snooze", "Snooze", nop)}, "foreground", "go-toast-launch:test_id"},
+		{"arguments", []NotificationOption{WithAction(DefaultAction, "", nop), WithActivationArguments("test_args")}, "foreground", "test_args"},
+		{"activation type", []NotificationOption{WithAction(DefaultAction, "", nop), WithActivationType("background")}, "background", "go-toast-launch:test_id"},
+	} {
+		n := &Notification{ID: "test_id", ActivationType: "protocol"}
+		for _, fn := range tt.opts {
+			fn(n)
+		}
+		if toast := toastXML(n); toast.ActivationType != tt.activationType || toast.Launch != tt.launch {
+			t.Errorf("%s: expected %q %q, got %q %q", tt.name, tt.activationType, tt.launch, toast.ActivationType, toast.Launch)
+		}
+	}
+}
+
+func TestProgressData(t *testing.T) {
+	for _, tt := range []struct {
+		value    float64
+		expected string
+	}{
+		{0, "0"},
+		{0.25, "0.25"},
+		{1.5, "1"},
+		{-1, "indeterminate"},
+	} {
+		data := progressData(&Progress{Value: tt.value, Status: "test_status"})
+		if data["progressValue"] != tt.expected || data["progressStatus"] != "test_status" {
+			t.Errorf("%v: expected value %q, got %v", tt.value, tt.expected, data)
+		}
+	}
+}
+
+// golden compares got with the content of the file, which is written instead with -update.
+func golden(t *testing.T, filename string, got []byte) {
+	t.Helper()
+	if *update {
+		checkErr(t, os.WriteFile(filename, got, 0644))
+		return
+	}
+	expected, err := os.ReadFile(filename)
+	checkErr(t, err)
+	if string(got) != string(expected) {
+		t.Errorf("%s: expected\n%s\ngot\n%s", filename, expected, got)
+	}
+}
@@ -0,0 +1,166 @@
+package toast
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// workerRequest is a line written to the stdin of a worker.
+type workerRequest struct {
+	// Assigned by worker.do, the response has the same
+	ID uint64 `json:"id"`
+
+	// show, update (the data of a toast) or remove
+	Op string `json:"op"`
+
+	AppID string `json:"app_id"`
+	XML   string `json:"xml,omitempty"`
+	Tag   string `json:"tag"`
+	Group string `json:"group"`
+
+	// The ExpirationTime of the toast, in milliseconds from now
+	Expiration int64 `json:"expiration,omitempty"`
+
+	// The NotificationData of the toast
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// workerResponse is a line written by a worker to its stdout once a request is done.
+type workerResponse struct {
+	ID    uint64 `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+// worker is a long-lived process reading newline-delimited JSON requests on its stdin,
+// and writing a response with the same id for each of them on its stdout, in any order.
+type worker struct {
+	cmd *exec.Cmd
+
+	// written by one request at a time, apart from mu for the responses to be read meanwhile
+	wmu   sync.Mutex
+	stdin io.WriteCloser
+
+	mu      sync.Mutex
+	lastID  uint64
+	pending map[uint64]chan error
+	err     error // why the worker exited, nil while it's running
+}
+
+// startWorker starts cmd as a worker, stopped by closing its stdin.
+func startWorker(cmd *exec.Cmd) (*worker, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	w := &worker{cmd: cmd, stdin: stdin, pending: make(map[uint64]chan error)}
+	go w.read(stdout, &stderr)
+	return w, nil
+}
+
+func (w *worker) read(stdout io.Reader, stderr *bytes.Buffer) {
+	lines := bufio.NewScanner(stdout)
+	for lines.Scan() {
+		var res workerResponse
+		// anything else the process writes is ignored
+		if json.Unmarshal(lines.Bytes(), &res) != nil || res.ID == 0 {
+			continue
+		}
+		w.mu.Lock()
+		done, ok := w.pending[res.ID]
+		delete(w.pending, res.ID)
+		w.mu.Unlock()
+		if !ok {
+			continue
+		}
+		if len(res.Error) != 0 {
+			done <- fmt.Errorf("toast: worker: %s", res.Error)
+		} else {
+			done <- nil
+		}
+	}
+
+	reason := "toast: worker exited"
+	if err := w.cmd.Wait(); err != nil {
+		reason += ": " + err.Error()
+	}
+	if msg := strings.TrimSpace(stderr.String()); len(msg) != 0 {
+		reason += ": " + msg
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.err = errors.New(reason)
+	for id, done := range w.pending {
+		done <- w.err
+		delete(w.pending, id)
+	}
+}
+
+// do sends the request and waits for its response, the error of the worker if it isn't nil.
+func (w *worker) do(ctx context.Context, req workerRequest) error {
+	done := make(chan error, 1)
+	w.mu.Lock()
+	if w.err != nil {
+		w.mu.Unlock()
+		return w.err
+	}
+	w.lastID++
+	req.ID = w.lastID
+	w.pending[req.ID] = done
+	w.mu.Unlock()
+
+	line, err := json.Marshal(req)
+	if err == nil {
+		w.wmu.Lock()
+		_, err = w.stdin.Write(append(line, '\n'))
+		w.wmu.Unlock()
+	}
+	if err != nil {
+		w.forget(req.ID)
+		return err
+	}
+
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		w.forget(req.ID)
+		return ctx.Err()
+	}
+}
+
+func (w *worker) forget(id uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.pending, id)
+}
+
+// exited reports whether the worker process is gone.
+func (w *worker) exited() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err != nil
+}
+
+// close has the worker exit once it has read the pending requests.
+func (w *worker) close() error {
+	w.wmu.Lock()
+	defer w.wmu.Unlock()
+	return w.stdin.Close()
+}
@@ -0,0 +1,124 @@
+package toast
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestFakeWorker is the worker process started by startFakeWorker, answering the requests by their tag:
+// fail fails, slow is answered after the next ones and exit exits.
+func TestFakeWorker(t *testing.T) {
+	if os.Getenv("GO_TOAST_FAKE_WORKER") != "1" {
+		t.Skip("started by the worker tests")
+	}
+	var mu sync.Mutex
+	respond := func(res workerResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		bs, _ := json.Marshal(res)
+		fmt.Println(string(bs))
+	}
+	fmt.Println("not a response")
+	lines := bufio.NewScanner(os.Stdin)
+	for lines.Scan() {
+		var req workerRequest
+		if err := json.Unmarshal(lines.Bytes(), &req); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		switch req.Tag {
+		case "fail":
+			respond(workerResponse{ID: req.ID, Error: "failing " + req.Op})
+		case "slow":
+			go func(id uint64) {
+				time.Sleep(200 * time.Millisecond)
+				respond(workerResponse{ID: id})
+			}(req.ID)
+		case "exit":
+			fmt.Fprintln(os.Stderr, "exiting")
+			os.Exit(3)
+		default:
+			respond(workerResponse{ID: req.ID})
+		}
+	}
+	time.Sleep(300 * time.Millisecond)
+	os.Exit(0)
+}
+
+func startFakeWorker(t *testing.T) *worker {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFakeWorker$")
+	cmd.Env = append(os.Environ(), "GO_TOAST_FAKE_WORKER=1")
+	w, err := startWorker(cmd)
+	checkErr(t, err)
+	t.Cleanup(func() {
+		_ = w.close()
+	})
+	return w
+}
+
+func TestWorker(t *testing.T) {
+	w := startFakeWorker(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 11)
+	for _, tag := range []string{"slow", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10"} {
+		wg.Add(1)
+		go func(tag string) {
+			defer wg.Done()
+			errs <- w.do(ctx, workerRequest{Op: "show", Tag: tag, XML: "<toast/>"})
+		}(tag)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		checkErr(t, err)
+	}
+
+	if err := w.do(ctx, workerRequest{Op: "remove", Tag: "fail"}); err == nil || !strings.Contains(err.Error(), "failing remove") {
+		t.Errorf("expected the error of the worker, got %v", err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := w.do(timeout, workerRequest{Op: "show", Tag: "slow"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	// the late response is ignored
+	checkErr(t, w.do(ctx, workerRequest{Op: "show", Tag: "slow"}))
+}
+
+func TestWorker_exit(t *testing.T) {
+	w := startFakeWorker(t)
+	ctx := context.Background()
+
+	err := w.do(ctx, workerRequest{Op: "show", Tag: "exit"})
+	if err == nil || !strings.Contains(err.Error(), "exiting") {
+		t.Errorf("expected the worker to exit with its stderr, got %v", err)
+	}
+	if !w.exited() {
+		t.Error("expected the worker to have exited")
+	}
+	if err = w.do(ctx, workerRequest{Op: "show"}); err == nil {
+		t.Error("expected an error once the worker exited")
+	}
+
+	w = startFakeWorker(t)
+	checkErr(t, w.do(ctx, workerRequest{Op: "show"}))
+	checkErr(t, w.close())
+	for deadline := time.Now().Add(5 * time.Second); !w.exited(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the worker to exit once its stdin is closed")
+		}
+	}
+}